@@ -0,0 +1,122 @@
+/* SPDX-License-Identifier: BSD-2-Clause
+
+Copyright (c) 2020-2026, Jef Oliver
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without modification,
+are permitted provided that the following conditions are met:
+
+  1. Redistributions of source code must retain the above copyright notice, this
+     list of conditions and the following disclaimer.
+
+  2. Redistributions in binary form must reproduce the above copyright notice
+     this list of conditions and the following disclaimer in the documentation
+     and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package cookie
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"eljef.dev/go/auth/pkg/crypt"
+)
+
+type codec struct {
+	block  crypt.Block
+	config Config
+}
+
+// Decode decrypts the value of the named cookie into the provided value
+func (c *codec) Decode(name string, value string, dst any) error {
+	if value == "" {
+		return crypt.ErrNoData
+	}
+
+	if len(name)+len(value)+1 > c.config.MaxLength {
+		return ErrTooLarge
+	}
+
+	encryptedData, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return fmt.Errorf("%w: %v", crypt.ErrInvalidEncoding, err)
+	}
+
+	data, err := c.block.DecryptWithAAD(encryptedData, []byte(name))
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(data, dst)
+}
+
+// Encode encrypts the provided value for the named cookie
+func (c *codec) Encode(name string, value any) (string, error) {
+	if name == "" {
+		return "", errors.New("no cookie name provided")
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+
+	encryptedData, err := c.block.EncryptWithAAD(data, []byte(name))
+	if err != nil {
+		return "", err
+	}
+
+	ret := base64.RawURLEncoding.EncodeToString(encryptedData)
+	if len(name)+len(ret)+1 > c.config.MaxLength {
+		return "", ErrTooLarge
+	}
+
+	return ret, nil
+}
+
+// ReadCookie reads the named cookie from a request and decodes it into the provided value. If the cookie is not
+// present, http.ErrNoCookie is returned.
+func (c *codec) ReadCookie(r *http.Request, name string, dst any) error {
+	cookie, err := r.Cookie(name)
+	if err != nil {
+		return err
+	}
+
+	return c.Decode(name, cookie.Value, dst)
+}
+
+// SetCookie encodes the provided value and sets it as the named cookie on a response
+func (c *codec) SetCookie(w http.ResponseWriter, name string, value any) error {
+	encoded, err := c.Encode(name, value)
+	if err != nil {
+		return err
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Domain:   c.config.Domain,
+		HttpOnly: c.config.HTTPOnly,
+		MaxAge:   c.config.MaxAge,
+		Name:     name,
+		Path:     c.config.Path,
+		SameSite: c.config.SameSite,
+		Secure:   c.config.Secure,
+		Value:    encoded,
+	})
+
+	return nil
+}
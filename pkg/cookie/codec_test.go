@@ -0,0 +1,199 @@
+/* SPDX-License-Identifier: BSD-2-Clause
+
+Copyright (c) 2020-2026, Jef Oliver
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without modification,
+are permitted provided that the following conditions are met:
+
+  1. Redistributions of source code must retain the above copyright notice, this
+     list of conditions and the following disclaimer.
+
+  2. Redistributions in binary form must reproduce the above copyright notice
+     this list of conditions and the following disclaimer in the documentation
+     and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package cookie
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type testState struct {
+	Provider string `json:"provider"`
+	State    string `json:"state"`
+}
+
+// newTestCodec returns a codec for testing
+func newTestCodec(t *testing.T, maxLength int) *codec {
+	t.Helper()
+
+	config := GetConfigDefaults()
+	config.MaxLength = maxLength
+
+	return &codec{block: newTestBlock(t), config: config}
+}
+
+// nolint: gocognit
+func Test_codecFullRun(t *testing.T) {
+	tests := []struct {
+		name       string
+		encodeName string
+		decodeName string
+		wantErr    bool
+	}{
+		{"good", "oauth_state", "oauth_state", false},
+		{"different cookie name", "oauth_state", "session", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			want := testState{Provider: "github", State: "abc123"}
+			tester := newTestCodec(t, 4096)
+
+			encoded, err := tester.Encode(tt.encodeName, want)
+			if err != nil {
+				t.Fatalf("codec.Encode() error = %v", err)
+			}
+			if strings.ContainsAny(encoded, "+/=;, ") {
+				t.Errorf("codec.Encode() = %s, want URL and cookie safe value", encoded)
+			}
+
+			var got testState
+			err = tester.Decode(tt.decodeName, encoded, &got)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("codec.Decode() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, want) {
+				t.Errorf("codec.Decode() = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func Test_codecDecode(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		wantErr error
+	}{
+		{"no value", "", nil},
+		{"too large", strings.Repeat("a", 100), ErrTooLarge},
+		{"bad encoding", "not*base64", nil},
+		{"bad data", "dGhpcyBpcyBub3QgZW5jcnlwdGVk", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got testState
+
+			tester := newTestCodec(t, 64)
+
+			err := tester.Decode("oauth_state", tt.value, &got)
+			if err == nil {
+				t.Error("codec.Decode() error = nil, want error")
+				return
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("codec.Decode() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_codecEncode(t *testing.T) {
+	tests := []struct {
+		name      string
+		cookie    string
+		value     any
+		maxLength int
+		wantErr   error
+	}{
+		{"no name", "", testState{}, 4096, nil},
+		{"bad value", "oauth_state", func() {}, 4096, nil},
+		{"too large", "oauth_state", testState{State: strings.Repeat("a", 100)}, 128, ErrTooLarge},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tester := newTestCodec(t, tt.maxLength)
+
+			got, err := tester.Encode(tt.cookie, tt.value)
+			if err == nil {
+				t.Errorf("codec.Encode() = %s, want error", got)
+				return
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("codec.Encode() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// nolint: gocognit
+func Test_codecCookies(t *testing.T) {
+	t.Run("set and read cookie", func(t *testing.T) {
+		want := testState{Provider: "github", State: "abc123"}
+		tester := newTestCodec(t, 4096)
+
+		recorder := httptest.NewRecorder()
+		if err := tester.SetCookie(recorder, "oauth_state", want); err != nil {
+			t.Fatalf("codec.SetCookie() error = %v", err)
+		}
+
+		cookies := recorder.Result().Cookies()
+		if len(cookies) != 1 {
+			t.Fatalf("codec.SetCookie() set %d cookies, want 1", len(cookies))
+		}
+
+		cookie := cookies[0]
+		if !cookie.Secure || !cookie.HttpOnly || cookie.SameSite != http.SameSiteLaxMode || cookie.Path != "/" {
+			t.Errorf("codec.SetCookie() cookie = %v, want secure, http only, lax, / path cookie", cookie)
+		}
+
+		request := httptest.NewRequest(http.MethodGet, "/", nil)
+		request.AddCookie(cookie)
+
+		var got testState
+		if err := tester.ReadCookie(request, "oauth_state", &got); err != nil {
+			t.Fatalf("codec.ReadCookie() error = %v", err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("codec.ReadCookie() = %v, want %v", got, want)
+		}
+
+		if err := tester.ReadCookie(request, "missing", &got); !errors.Is(err, http.ErrNoCookie) {
+			t.Errorf("codec.ReadCookie() error = %v, want %v", err, http.ErrNoCookie)
+		}
+	})
+
+	t.Run("set cookie error", func(t *testing.T) {
+		tester := newTestCodec(t, 16)
+
+		recorder := httptest.NewRecorder()
+		if err := tester.SetCookie(recorder, "oauth_state", testState{}); err == nil {
+			t.Error("codec.SetCookie() error = nil, want error")
+		}
+		if len(recorder.Result().Cookies()) != 0 {
+			t.Error("codec.SetCookie() set cookie on error")
+		}
+	})
+}
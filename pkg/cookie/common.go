@@ -0,0 +1,80 @@
+/* SPDX-License-Identifier: BSD-2-Clause
+
+Copyright (c) 2020-2026, Jef Oliver
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without modification,
+are permitted provided that the following conditions are met:
+
+  1. Redistributions of source code must retain the above copyright notice, this
+     list of conditions and the following disclaimer.
+
+  2. Redistributions in binary form must reproduce the above copyright notice
+     this list of conditions and the following disclaimer in the documentation
+     and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package cookie
+
+import (
+	"errors"
+	"net/http"
+
+	"eljef.dev/go/auth/pkg/crypt"
+)
+
+// ErrTooLarge is returned when an encoded cookie is larger than the configured maximum length
+var ErrTooLarge = errors.New("encoded cookie exceeds maximum length")
+
+// GetConfigDefaults returns sane defaults to be used for cookies.
+//
+// The maximum length is the 4096 byte limit browsers place on the name and value of a cookie.
+func GetConfigDefaults() Config {
+	return Config{
+		HTTPOnly:  true,
+		MaxLength: 4096,
+		Path:      "/",
+		SameSite:  http.SameSiteLaxMode,
+		Secure:    true,
+	}
+}
+
+// NewCodec returns a Codec that encrypts cookie values with the provided Block.
+func NewCodec(block crypt.Block, config Config) (Codec, error) {
+	if block == nil {
+		return nil, errors.New("no block provided")
+	}
+
+	if err := validateConfig(&config); err != nil {
+		return nil, err
+	}
+
+	return &codec{
+		block:  block,
+		config: config,
+	}, nil
+}
+
+// validateConfig validates that the provided config can be used.
+func validateConfig(config *Config) error {
+	if config.MaxLength < 1 {
+		return errors.New("max length must be greater than zero")
+	}
+
+	if config.SameSite == http.SameSiteNoneMode && !config.Secure {
+		return errors.New("SameSite=None cookies must be secure")
+	}
+
+	return nil
+}
@@ -0,0 +1,112 @@
+/* SPDX-License-Identifier: BSD-2-Clause
+
+Copyright (c) 2020-2026, Jef Oliver
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without modification,
+are permitted provided that the following conditions are met:
+
+  1. Redistributions of source code must retain the above copyright notice, this
+     list of conditions and the following disclaimer.
+
+  2. Redistributions in binary form must reproduce the above copyright notice
+     this list of conditions and the following disclaimer in the documentation
+     and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package cookie
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+
+	"eljef.dev/go/auth/pkg/crypt"
+)
+
+// newTestBlock returns a crypt.Block for testing
+func newTestBlock(t *testing.T) crypt.Block {
+	t.Helper()
+
+	block, err := crypt.NewBlock([]byte("testKeySixteen16"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return block
+}
+
+func Test_GetConfigDefaults(t *testing.T) {
+	t.Run("good", func(t *testing.T) {
+		want := Config{
+			HTTPOnly:  true,
+			MaxLength: 4096,
+			Path:      "/",
+			SameSite:  http.SameSiteLaxMode,
+			Secure:    true,
+		}
+
+		if got := GetConfigDefaults(); !reflect.DeepEqual(got, want) {
+			t.Errorf("GetConfigDefaults() got = %v, want = %v", got, want)
+		}
+	})
+}
+
+func TestNewCodec(t *testing.T) {
+	block := newTestBlock(t)
+
+	tests := []struct {
+		name    string
+		block   crypt.Block
+		config  Config
+		wantErr bool
+	}{
+		{"no block", nil, GetConfigDefaults(), true},
+		{"bad config", block, Config{}, true},
+		{"good", block, GetConfigDefaults(), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewCodec(tt.block, tt.config)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewCodec() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if (got == nil) != tt.wantErr {
+				t.Errorf("NewCodec() got = %v, wantNil = %v", got, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_validateConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		wantErr bool
+		data    *Config
+	}{
+		{"no max length", true, &Config{}},
+		{"insecure same site none", true, &Config{MaxLength: 1, SameSite: http.SameSiteNoneMode}},
+		{"secure same site none", false, &Config{MaxLength: 1, SameSite: http.SameSiteNoneMode, Secure: true}},
+		{"good", false, &Config{MaxLength: 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := validateConfig(tt.data); (err != nil) != tt.wantErr {
+				t.Errorf("validateConfig() err = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
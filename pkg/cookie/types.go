@@ -0,0 +1,54 @@
+/* SPDX-License-Identifier: BSD-2-Clause
+
+Copyright (c) 2020-2026, Jef Oliver
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without modification,
+are permitted provided that the following conditions are met:
+
+  1. Redistributions of source code must retain the above copyright notice, this
+     list of conditions and the following disclaimer.
+
+  2. Redistributions in binary form must reproduce the above copyright notice
+     this list of conditions and the following disclaimer in the documentation
+     and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package cookie
+
+import (
+	"net/http"
+)
+
+// Codec is an interface that encodes values into encrypted, authenticated, URL safe cookie values.
+//
+// The name of the cookie is bound to the encrypted value as additional authenticated data, so a value created for one
+// cookie is rejected when replayed into another.
+type Codec interface {
+	Decode(string, string, any) error                 // Decode decrypts the value of the named cookie into the provided value
+	Encode(string, any) (string, error)               // Encode encrypts the provided value for the named cookie
+	ReadCookie(*http.Request, string, any) error      // ReadCookie reads the named cookie from a request and decodes it into the provided value
+	SetCookie(http.ResponseWriter, string, any) error // SetCookie encodes the provided value and sets it as the named cookie on a response
+}
+
+// Config holds the configuration values for cookies created by a Codec.
+type Config struct {
+	Domain    string        `json:"domain,omitempty" toml:"domain"`         // Domain is the domain the cookie is valid for.
+	HTTPOnly  bool          `json:"http_only,omitempty" toml:"http_only"`   // HTTPOnly prevents scripts from accessing the cookie.
+	MaxAge    int           `json:"max_age,omitempty" toml:"max_age"`       // MaxAge is the lifetime of the cookie, in seconds. Zero creates a session cookie.
+	MaxLength int           `json:"max_length,omitempty" toml:"max_length"` // MaxLength is the maximum length, in bytes, of the cookie name and encoded value.
+	Path      string        `json:"path,omitempty" toml:"path"`             // Path is the path the cookie is valid for.
+	SameSite  http.SameSite `json:"same_site,omitempty" toml:"same_site"`   // SameSite is the SameSite mode of the cookie.
+	Secure    bool          `json:"secure,omitempty" toml:"secure"`         // Secure restricts the cookie to HTTPS connections.
+}
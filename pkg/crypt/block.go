@@ -30,75 +30,108 @@ package crypt
 import (
 	"crypto/cipher"
 	"crypto/rand"
-	"encoding/hex"
-	"errors"
 	"io"
 )
 
+// block holds an AEAD built once when the block is created. The AEADs used hold no per-call state, so a block is safe
+// for concurrent use.
 type block struct {
-	aes cipher.Block
+	aead      cipher.AEAD
+	algorithm Algorithm
+	keyID     uint32
 }
 
 // Decrypt decrypts the provided data
 func (b *block) Decrypt(data []byte) ([]byte, error) {
-	if data == nil {
-		return nil, errors.New("no data provided to encrypt")
-	}
-
-	gcm, err := cipher.NewGCM(b.aes)
-	if err != nil {
-		return nil, err
-	}
-
-	nonceSize := gcm.NonceSize()
-	nonce, ciphertext := data[:nonceSize], data[nonceSize:]
-
-	return gcm.Open(nil, nonce, ciphertext, nil)
+	return b.DecryptWithAAD(data, nil)
 }
 
 // DecryptFromString decrypts data stored in a hex encoded string
 func (b *block) DecryptFromString(data string) ([]byte, error) {
-	if data == "" {
-		return nil, errors.New("no data provided to decrypt")
-	}
+	return b.DecryptFromStringWithAAD(data, nil)
+}
 
-	dataToDecode, err := hex.DecodeString(data)
+// DecryptFromStringWithAAD decrypts data stored in a hex encoded string, authenticating it against the provided
+// additional data
+func (b *block) DecryptFromStringWithAAD(data string, aad []byte) ([]byte, error) {
+	dataToDecode, err := decodeString(data, EncodingHex)
 	if err != nil {
 		return nil, err
 	}
 
-	return b.Decrypt(dataToDecode)
+	return b.DecryptWithAAD(dataToDecode, aad)
 }
 
-// Encrypt encrypts the provided data
-func (b *block) Encrypt(data []byte) ([]byte, error) {
-	var encryptedText []byte
+// DecryptWithAAD decrypts the provided data, authenticating it against the provided additional data
+func (b *block) DecryptWithAAD(data []byte, aad []byte) ([]byte, error) {
+	if len(data) == 0 {
+		return nil, ErrNoData
+	}
 
-	if data == nil {
-		return nil, errors.New("no data provided to encrypt")
+	env, err := ParseEnvelope(data)
+	if err == nil && !env.Legacy && env.Algorithm == b.algorithm {
+		var ret []byte
+		if ret, err = openEnvelope(b.aead, &env, aad); err == nil {
+			return ret, nil
+		}
 	}
 
-	gcm, err := cipher.NewGCM(b.aes)
-	if err != nil {
+	if b.algorithm != AlgorithmAESGCM {
+		if err == nil {
+			// the data is not a framed envelope for this block's algorithm
+			err = ErrAuthenticationFailed
+		}
+
 		return nil, err
 	}
 
-	nonce := make([]byte, gcm.NonceSize())
-	if _, err = io.ReadFull(rand.Reader, nonce); err == nil {
-		encryptedText = gcm.Seal(nonce, nonce, data, nil)
+	// Data that cannot be opened as a framed envelope may still be legacy data whose nonce happens to start with the
+	// envelope magic, so a legacy open is always attempted before failing.
+	if env, err = parseLegacyEnvelope(data); err != nil {
+		return nil, err
 	}
 
-	return encryptedText, err
+	return openEnvelope(b.aead, &env, aad)
+}
+
+// Encrypt encrypts the provided data, returning it framed in an Envelope
+func (b *block) Encrypt(data []byte) ([]byte, error) {
+	return b.EncryptWithAAD(data, nil)
 }
 
 // EncryptToString encrypts the provided data and returns it as a nex encoded string
 func (b *block) EncryptToString(data []byte) (string, error) {
+	return b.EncryptToStringWithAAD(data, nil)
+}
+
+// EncryptToStringWithAAD encrypts the provided data, binding it to the provided additional data, and returns it as a
+// hex encoded string
+func (b *block) EncryptToStringWithAAD(data []byte, aad []byte) (string, error) {
 	var ret string
 
-	encryptedData, err := b.Encrypt(data)
+	encryptedData, err := b.EncryptWithAAD(data, aad)
 	if err == nil {
-		ret = hex.EncodeToString(encryptedData)
+		ret = EncodingHex.EncodeToString(encryptedData)
 	}
 
 	return ret, err
 }
+
+// EncryptWithAAD encrypts the provided data, binding it to the provided additional data
+func (b *block) EncryptWithAAD(data []byte, aad []byte) ([]byte, error) {
+	var encryptedText []byte
+
+	if data == nil {
+		return nil, ErrNoData
+	}
+
+	env := newEnvelope(b.algorithm, b.keyID)
+	env.Nonce = make([]byte, b.aead.NonceSize())
+	_, err := io.ReadFull(rand.Reader, env.Nonce)
+	if err == nil {
+		env.Ciphertext = b.aead.Seal(nil, env.Nonce, data, env.additionalData(aad))
+		encryptedText = env.Bytes()
+	}
+
+	return encryptedText, err
+}
@@ -29,32 +29,46 @@ package crypt
 
 import (
 	"bytes"
-	"crypto/aes"
+	"errors"
+	"sync"
 	"testing"
 )
 
-type badCipherBlock struct{}
+// newTestBlock returns an AES GCM block using the provided key
+func newTestBlock(t testing.TB, key string) *block {
+	t.Helper()
 
-func (b *badCipherBlock) BlockSize() int      { return 0 }
-func (b *badCipherBlock) Encrypt(_, _ []byte) {}
-func (b *badCipherBlock) Decrypt(_, _ []byte) {}
+	ret, err := newAESBlock([]byte(key), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return ret
+}
+
+// newTestXChaChaBlock returns an XChaCha20-Poly1305 block using the provided key
+func newTestXChaChaBlock(t testing.TB, key string) *block {
+	t.Helper()
+
+	ret, err := NewXChaChaBlock([]byte(key))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return ret.(*block)
+}
 
 // nolint: gocognit
 func Test_blockDecrypt(t *testing.T) {
 	tests := []struct {
-		name      string
-		key       string
-		badCipher bool
-		data      []byte
-		want      []byte
-		wantErr   bool
+		name    string
+		key     string
+		data    []byte
+		want    []byte
+		wantErr bool
 	}{
-		{"no data", "testKeySixteen16", false, nil, nil, true},
-		{"NewGCM error", "testKeySixteen16", true,
-			[]byte{202, 202, 128, 183, 95, 130, 153, 150, 136, 28, 103, 56, 208, 21, 82, 188, 119, 13, 218, 189, 60, 44,
-				159, 101, 34, 236, 28, 56, 32, 22, 63, 26, 137, 246, 193, 77, 21, 16, 179, 185, 0, 188, 160, 23, 30},
-			nil, true},
-		{"good", "testKeySixteen16", false,
+		{"no data", "testKeySixteen16", nil, nil, true},
+		{"good", "testKeySixteen16",
 			[]byte{202, 202, 128, 183, 95, 130, 153, 150, 136, 28, 103, 56, 208, 21, 82, 188, 119, 13, 218, 189, 60, 44,
 				159, 101, 34, 236, 28, 56, 32, 22, 63, 26, 137, 246, 193, 77, 21, 16, 179, 185, 0, 188, 160, 23, 30},
 			[]byte{116, 104, 105, 115, 32, 105, 115, 32, 116, 101, 115, 116, 32, 100, 97, 116, 97},
@@ -63,16 +77,7 @@ func Test_blockDecrypt(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			tester := &block{}
-			if tt.badCipher {
-				tester.aes = &badCipherBlock{}
-			} else {
-				rAES, err := aes.NewCipher([]byte(tt.key))
-				if err != nil {
-					t.Fatal(err)
-				}
-				tester.aes = rAES
-			}
+			tester := newTestBlock(t, tt.key)
 
 			got, err := tester.Decrypt(tt.data)
 			if (err != nil) != tt.wantErr {
@@ -106,16 +111,9 @@ func Test_blockDecryptFromString(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			var got []byte
+			tester := newTestBlock(t, tt.key)
 
-			tester := &block{}
-			rAES, err := aes.NewCipher([]byte(tt.key))
-			if err != nil {
-				t.Fatal(err)
-			}
-			tester.aes = rAES
-
-			got, err = tester.DecryptFromString(tt.data)
+			got, err := tester.DecryptFromString(tt.data)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("blockDecryptFromString() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -131,34 +129,21 @@ func Test_blockDecryptFromString(t *testing.T) {
 // nolint: gocognit
 func Test_blockEncrypt(t *testing.T) {
 	tests := []struct {
-		name      string
-		key       string
-		badCipher bool
-		data      []byte
-		wantErr   bool
-		wantNil   bool
+		name    string
+		key     string
+		data    []byte
+		wantErr bool
+		wantNil bool
 	}{
-		{"no data", "testKeySixteen16", false, nil, true, true},
-		{"NewGCM error", "testKeySixteen16", true,
-			[]byte{116, 104, 105, 115, 32, 105, 115, 32, 116, 101, 115, 116, 32, 100, 97, 116, 97},
-			true, true},
-		{"good", "testKeySixteen16", false,
+		{"no data", "testKeySixteen16", nil, true, true},
+		{"good", "testKeySixteen16",
 			[]byte{116, 104, 105, 115, 32, 105, 115, 32, 116, 101, 115, 116, 32, 100, 97, 116, 97},
 			false, false},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			tester := &block{}
-			if tt.badCipher {
-				tester.aes = &badCipherBlock{}
-			} else {
-				rAES, err := aes.NewCipher([]byte(tt.key))
-				if err != nil {
-					t.Fatal(err)
-				}
-				tester.aes = rAES
-			}
+			tester := newTestBlock(t, tt.key)
 
 			got, err := tester.Encrypt(tt.data)
 			if (err != nil) != tt.wantErr {
@@ -190,16 +175,9 @@ func Test_blockEncryptToString(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			var got string
-
-			tester := &block{}
-			rAES, err := aes.NewCipher([]byte(tt.key))
-			if err != nil {
-				t.Fatal(err)
-			}
-			tester.aes = rAES
+			tester := newTestBlock(t, tt.key)
 
-			got, err = tester.EncryptToString(tt.data)
+			got, err := tester.EncryptToString(tt.data)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("block.EncryptToString() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -222,14 +200,9 @@ func Test_blockFullRun(t *testing.T) {
 
 		data := []byte("this is test data")
 
-		tester := &block{}
-		rAES, err := aes.NewCipher([]byte("testKeySixteen16"))
-		if err != nil {
-			t.Fatal(err)
-		}
-		tester.aes = rAES
+		tester := newTestBlock(t, "testKeySixteen16")
 
-		encryptedData, err = tester.EncryptToString(data)
+		encryptedData, err := tester.EncryptToString(data)
 		if err != nil {
 			t.Errorf("block.EncryptToString() error = %v", err)
 			return
@@ -256,3 +229,423 @@ func Test_blockFullRun(t *testing.T) {
 		}
 	})
 }
+
+// nolint: gocognit
+func Test_blockAADFullRun(t *testing.T) {
+	tests := []struct {
+		name       string
+		encryptAAD []byte
+		decryptAAD []byte
+		wantErr    bool
+	}{
+		{"no aad", nil, nil, false},
+		{"matching aad", []byte("user:1234"), []byte("user:1234"), false},
+		{"different aad", []byte("user:1234"), []byte("user:5678"), true},
+		{"missing aad", []byte("cookie:oauth_state"), nil, true},
+		{"unexpected aad", nil, []byte("cookie:oauth_state"), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := []byte("this is test data")
+
+			tester := newTestBlock(t, "testKeySixteen16")
+
+			encryptedData, err := tester.EncryptWithAAD(data, tt.encryptAAD)
+			if err != nil {
+				t.Fatalf("block.EncryptWithAAD() error = %v", err)
+			}
+
+			decryptedData, err := tester.DecryptWithAAD(encryptedData, tt.decryptAAD)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("block.DecryptWithAAD() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && !bytes.Equal(decryptedData, data) {
+				t.Errorf("block.DecryptWithAAD() = %v, want %v", decryptedData, data)
+				return
+			}
+
+			encryptedString, err := tester.EncryptToStringWithAAD(data, tt.encryptAAD)
+			if err != nil {
+				t.Fatalf("block.EncryptToStringWithAAD() error = %v", err)
+			}
+
+			decryptedData, err = tester.DecryptFromStringWithAAD(encryptedString, tt.decryptAAD)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("block.DecryptFromStringWithAAD() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && !bytes.Equal(decryptedData, data) {
+				t.Errorf("block.DecryptFromStringWithAAD() = %v, want %v", decryptedData, data)
+			}
+		})
+	}
+}
+
+// nolint: gocognit
+func Test_blockEnvelope(t *testing.T) {
+	tests := []struct {
+		name    string
+		tamper  func([]byte)
+		wantErr bool
+	}{
+		{"unmodified", func(_ []byte) {}, false},
+		{"modified key id", func(data []byte) { data[8] = 9 }, true},
+		{"modified ciphertext", func(data []byte) { data[len(data)-1] ^= 0xff }, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := []byte("this is test data")
+
+			tester := newTestBlock(t, "testKeySixteen16")
+
+			encryptedData, err := tester.Encrypt(data)
+			if err != nil {
+				t.Fatalf("block.Encrypt() error = %v", err)
+			}
+
+			env, err := ParseEnvelope(encryptedData)
+			if err != nil {
+				t.Fatalf("ParseEnvelope() error = %v", err)
+			}
+			if env.Legacy || env.Algorithm != AlgorithmAESGCM || env.Version != EnvelopeVersion {
+				t.Fatalf("block.Encrypt() envelope = %v, want framed AES-GCM envelope", env)
+			}
+
+			tt.tamper(encryptedData)
+
+			got, err := tester.Decrypt(encryptedData)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("block.Decrypt() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && !bytes.Equal(got, data) {
+				t.Errorf("block.Decrypt() = %v, want %v", got, data)
+			}
+		})
+	}
+}
+
+func Test_blockXChaChaDecryptFromString(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		want    []byte
+		wantErr bool
+	}{
+		{"no data", "", nil, true},
+		{"bad data", "8906ay9douigh89adjghfajkldgkljgzx", nil, true},
+		{"legacy aes data", "62cee51629a72beee654a8fbc6d81ed07e981c15889c80baf636b73f22f95cc0e659dbeebe278792c256dd096f",
+			nil, true},
+		{"good",
+			"454a4301020000000009919e46be1aa617994fee05496ce6fb728bb85c2d66b51eec8cbab6194ed28e3cbb801da69ddc0756655" +
+				"66372ba7b64c369b73b83467af5a7",
+			[]byte{116, 104, 105, 115, 32, 105, 115, 32, 116, 101, 115, 116, 32, 100, 97, 116, 97},
+			false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tester := newTestXChaChaBlock(t, "testKeyThirtyTwoBytesLong32Bytes")
+
+			got, err := tester.DecryptFromString(tt.data)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("block.DecryptFromString() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if !bytes.Equal(got, tt.want) {
+				t.Errorf("block.DecryptFromString() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_blockXChaChaEncrypt(t *testing.T) {
+	tests := []struct {
+		name    string
+		key     string
+		data    []byte
+		wantErr bool
+	}{
+		{"no data", "testKeyThirtyTwoBytesLong32Bytes", nil, true},
+		{"good", "testKeyThirtyTwoBytesLong32Bytes", []byte("this is test data"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tester := newTestXChaChaBlock(t, tt.key)
+
+			got, err := tester.Encrypt(tt.data)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("block.Encrypt() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+
+			env, err := ParseEnvelope(got)
+			if err != nil {
+				t.Errorf("ParseEnvelope() error = %v", err)
+				return
+			}
+			if env.Algorithm != AlgorithmXChaCha20Poly1305 || len(env.Nonce) != 24 {
+				t.Errorf("block.Encrypt() envelope = %v, want XChaCha20-Poly1305 with 24 byte nonce", env)
+			}
+		})
+	}
+}
+
+// nolint: gocognit
+func Test_blockXChaChaFullRun(t *testing.T) {
+	tests := []struct {
+		name       string
+		encryptAAD []byte
+		decryptAAD []byte
+		wantErr    bool
+	}{
+		{"no aad", nil, nil, false},
+		{"matching aad", []byte("user:1234"), []byte("user:1234"), false},
+		{"different aad", []byte("user:1234"), []byte("user:5678"), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := []byte("this is test data")
+
+			tester, err := NewXChaChaBlock([]byte("testKeyThirtyTwoBytesLong32Bytes"))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			encryptedData, err := tester.EncryptToStringWithAAD(data, tt.encryptAAD)
+			if err != nil {
+				t.Fatalf("block.EncryptToStringWithAAD() error = %v", err)
+			}
+
+			got, err := tester.DecryptFromStringWithAAD(encryptedData, tt.decryptAAD)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("block.DecryptFromStringWithAAD() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && !bytes.Equal(got, data) {
+				t.Errorf("block.DecryptFromStringWithAAD() = %v, want %v", got, data)
+			}
+		})
+	}
+}
+
+func Test_blockDecryptErrors(t *testing.T) {
+	aesBlock, err := NewBlock([]byte("testKeySixteen16"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	xchachaBlock, err := NewXChaChaBlock([]byte("testKeyThirtyTwoBytesLong32Bytes"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ring, err := NewKeyring(1, []byte("testKeySixteen16"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		block   Block
+		data    string
+		wantErr error
+	}{
+		{"aes no data", aesBlock, "", ErrNoData},
+		{"aes bad encoding", aesBlock, "not hex", ErrInvalidEncoding},
+		{"aes short nonce", aesBlock, "0102", ErrCiphertextTooShort},
+		{"aes short tag", aesBlock, "0102030405060708090a0b0c0d0e0f", ErrCiphertextTooShort},
+		{"aes modified", aesBlock,
+			"62cee51629a72beee654a8fbc6d81ed07e981c15889c80baf636b73f22f95cc0e659dbeebe278792c256dd0900",
+			ErrAuthenticationFailed},
+		{"xchacha no data", xchachaBlock, "", ErrNoData},
+		{"xchacha bad encoding", xchachaBlock, "not hex", ErrInvalidEncoding},
+		{"xchacha short header", xchachaBlock, "454a4301", ErrCiphertextTooShort},
+		{"xchacha short nonce", xchachaBlock, "454a430102000000000102", ErrCiphertextTooShort},
+		{"xchacha short tag", xchachaBlock,
+			"454a4301020000000001020304050607080910111213141516171819202122232401", ErrCiphertextTooShort},
+		{"xchacha legacy data", xchachaBlock,
+			"62cee51629a72beee654a8fbc6d81ed07e981c15889c80baf636b73f22f95cc0e659dbeebe278792c256dd096f",
+			ErrAuthenticationFailed},
+		{"xchacha bad version", xchachaBlock,
+			"454a4309020000000001020304050607080910111213141516171819202122232401", ErrAuthenticationFailed},
+		{"xchacha bad algorithm", xchachaBlock,
+			"454a4301070000000001020304050607080910111213141516171819202122232401", ErrAuthenticationFailed},
+		{"keyring bad version", ring,
+			"454a430901000000010102030405060708091011120102030405060708091011121314151617", ErrAuthenticationFailed},
+		{"keyring bad algorithm", ring,
+			"454a430107000000010102030405060708091011120102030405060708091011121314151617", ErrAuthenticationFailed},
+		{"keyring unknown key", ring,
+			"454a430101000000090102030405060708091011120102030405060708091011121314151617", ErrAuthenticationFailed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.block.DecryptFromString(tt.data)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("block.DecryptFromString() error = %v, want %v", err, tt.wantErr)
+			}
+			if got != nil {
+				t.Errorf("block.DecryptFromString() = %v, want nil", got)
+			}
+		})
+	}
+}
+
+// fuzzBlocks returns blocks for fuzz testing along with seed data encrypted by each
+func fuzzBlocks(f *testing.F) []Block {
+	f.Helper()
+
+	aesBlock, err := NewBlock([]byte("testKeySixteen16"))
+	if err != nil {
+		f.Fatal(err)
+	}
+	xchachaBlock, err := NewXChaChaBlock([]byte("testKeyThirtyTwoBytesLong32Bytes"))
+	if err != nil {
+		f.Fatal(err)
+	}
+
+	ret := []Block{aesBlock, xchachaBlock}
+	for _, b := range ret {
+		encryptedData, err := b.Encrypt([]byte("this is test data"))
+		if err != nil {
+			f.Fatal(err)
+		}
+		f.Add(encryptedData)
+	}
+
+	return ret
+}
+
+func FuzzBlockDecrypt(f *testing.F) {
+	blocks := fuzzBlocks(f)
+	f.Add([]byte{})
+	f.Add([]byte{'E', 'J', 'C', 1, 1})
+	f.Add([]byte{'E', 'J', 'C', 1, 2, 0, 0, 0, 0})
+	f.Add([]byte{202, 202, 128, 183, 95, 130, 153, 150, 136, 28, 103, 56, 208, 21, 82, 188, 119})
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		for _, b := range blocks {
+			got, err := b.Decrypt(data)
+			if err == nil && !bytes.Equal(got, []byte("this is test data")) {
+				t.Errorf("block.Decrypt() = %v, want error", got)
+			}
+		}
+	})
+}
+
+func FuzzBlockDecryptFromString(f *testing.F) {
+	blocks := fuzzBlocks(f)
+	f.Add([]byte(""))
+	f.Add([]byte("not hex"))
+	f.Add([]byte("454a4301020000000001"))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		for _, b := range blocks {
+			got, err := b.DecryptFromString(string(data))
+			if err == nil && !bytes.Equal(got, []byte("this is test data")) {
+				t.Errorf("block.DecryptFromString() = %v, want error", got)
+			}
+		}
+	})
+}
+
+// nolint: gocognit
+func Test_blockConcurrent(t *testing.T) {
+	tests := []struct {
+		name   string
+		tester *block
+	}{
+		{"aes-gcm", newTestBlock(t, "testKeySixteen16")},
+		{"xchacha20-poly1305", newTestXChaChaBlock(t, "testKeyThirtyTwoBytesLong32Bytes")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := []byte("this is test data")
+
+			shared, err := tt.tester.Encrypt(data)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var wg sync.WaitGroup
+			for range 16 {
+				wg.Go(func() {
+					for range 100 {
+						encrypted, err := tt.tester.EncryptWithAAD(data, []byte("aad"))
+						if err != nil {
+							t.Errorf("block.EncryptWithAAD() error = %v", err)
+							return
+						}
+
+						got, err := tt.tester.DecryptWithAAD(encrypted, []byte("aad"))
+						if err != nil || !bytes.Equal(got, data) {
+							t.Errorf("block.DecryptWithAAD() = %v, %v, want %v", got, err, data)
+							return
+						}
+
+						if got, err = tt.tester.Decrypt(shared); err != nil || !bytes.Equal(got, data) {
+							t.Errorf("block.Decrypt() = %v, %v, want %v", got, err, data)
+							return
+						}
+					}
+				})
+			}
+			wg.Wait()
+		})
+	}
+}
+
+func BenchmarkBlockDecrypt(b *testing.B) {
+	tester := newTestBlock(b, "testKeyThirtyTwoBytesLong32Bytes")
+
+	encrypted, err := tester.Encrypt(make([]byte, 256))
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			if _, err := tester.Decrypt(encrypted); err != nil {
+				b.Error(err)
+			}
+		}
+	})
+}
+
+func BenchmarkBlockEncrypt(b *testing.B) {
+	tester := newTestBlock(b, "testKeyThirtyTwoBytesLong32Bytes")
+	data := make([]byte, 256)
+
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			if _, err := tester.Encrypt(data); err != nil {
+				b.Error(err)
+			}
+		}
+	})
+}
+
+func BenchmarkXChaChaBlockEncrypt(b *testing.B) {
+	tester := newTestXChaChaBlock(b, "testKeyThirtyTwoBytesLong32Bytes")
+	data := make([]byte, 256)
+
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			if _, err := tester.Encrypt(data); err != nil {
+				b.Error(err)
+			}
+		}
+	})
+}
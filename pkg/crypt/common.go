@@ -28,19 +28,366 @@ OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
 package crypt
 
 import (
+	"bytes"
 	"crypto/aes"
+	"crypto/cipher"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"slices"
+	"time"
+
+	"eljef.dev/go/auth/pkg/hash"
+	"golang.org/x/crypto/chacha20poly1305"
+)
+
+var (
+	// ErrAuthenticationFailed is returned when encrypted data fails authentication, because it was modified, or was
+	// decrypted with the wrong key or additional data
+	ErrAuthenticationFailed = errors.New("encrypted data failed authentication")
+	// ErrCiphertextTooShort is returned when encrypted data is too short to hold its framing, nonce, and tag
+	ErrCiphertextTooShort = errors.New("encrypted data too short")
+	// ErrInvalidEncoding is returned when a string of encrypted data cannot be decoded
+	ErrInvalidEncoding = errors.New("invalid encrypted data encoding")
+	// ErrNoData is returned when no data is provided to encrypt or decrypt
+	ErrNoData = errors.New("no data provided")
 )
 
+// decodeString decodes a string of encrypted data with the provided encoding
+func decodeString(data string, encoding Encoding) ([]byte, error) {
+	if data == "" {
+		return nil, ErrNoData
+	}
+
+	ret, err := encoding.DecodeString(data)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidEncoding, err)
+	}
+
+	return ret, nil
+}
+
+// newAESBlock returns an AES GCM block that identifies its key with the provided key ID
+func newAESBlock(key []byte, keyID uint32) (*block, error) {
+	rAES, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return newGCMBlock(rAES, keyID)
+}
+
+// newGCMBlock returns a block using GCM with the provided cipher.Block
+func newGCMBlock(c cipher.Block, keyID uint32) (*block, error) {
+	aead, err := cipher.NewGCM(c)
+	if err != nil {
+		return nil, err
+	}
+
+	return &block{
+		aead:      aead,
+		algorithm: AlgorithmAESGCM,
+		keyID:     keyID,
+	}, nil
+}
+
+// NewBucketPadding returns a Padding that pads data to the smallest of the provided sizes it fits in. Data larger than
+// every size is padded to a multiple of the largest size.
+func NewBucketPadding(sizes ...int) (Padding, error) {
+	if len(sizes) == 0 {
+		return nil, errors.New("no bucket sizes provided")
+	}
+
+	buckets := slices.Clone(sizes)
+	slices.Sort(buckets)
+
+	if buckets[0] <= 0 {
+		return nil, fmt.Errorf("invalid bucket size: %d", buckets[0])
+	}
+
+	return &bucketPadding{
+		sizes: slices.Compact(buckets),
+	}, nil
+}
+
 // NewBlock returns an AES GCM Block to be used with Encrypt and Decrypt
 // functions.
 func NewBlock(key []byte) (Block, error) {
 	var ret Block
-	rAES, err := aes.NewCipher(key)
+	aesBlock, err := newAESBlock(key, 0)
 	if err == nil {
-		ret = &block{
-			aes: rAES,
-		}
+		ret = aesBlock
+	}
+
+	return ret, err
+}
+
+// NewBlockWithEncoding returns an AES GCM Block whose string functions use the provided encoding.
+func NewBlockWithEncoding(key []byte, encoding Encoding) (Block, error) {
+	aesBlock, err := NewBlock(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewEncodedBlock(aesBlock, encoding)
+}
+
+// NewDecryptReader returns a reader that decrypts a stream written by an encrypt writer from NewEncryptWriter. The
+// stream header is read from r before returning.
+//
+// Data returned by the reader has been authenticated, but a stream is only known to be complete once the reader
+// returns io.EOF. A stream that has been truncated or reordered returns an error instead.
+func NewDecryptReader(r io.Reader, key []byte) (io.Reader, error) {
+	var ret io.Reader
+	reader, err := newDecryptReader(r, key)
+	if err == nil {
+		ret = reader
 	}
 
 	return ret, err
 }
+
+// NewEncryptWriter returns a writer that encrypts data written to it as a chunked stream, writing the encrypted stream
+// to w. The key must be a valid AES key, as accepted by NewBlock. The stream header is written to w before returning.
+//
+// Close must be called to write the final chunk of the stream. Close does not close w.
+func NewEncryptWriter(w io.Writer, key []byte) (io.WriteCloser, error) {
+	var ret io.WriteCloser
+	writer, err := newEncryptWriter(w, key)
+	if err == nil {
+		ret = writer
+	}
+
+	return ret, err
+}
+
+// NewDeterministicBlock returns an AES-SIV DeterministicBlock. The provided key must be 32, 48 or 64 bytes, as it
+// holds two AES keys of equal size: the first half is used for authentication, and the second for encryption.
+func NewDeterministicBlock(key []byte) (DeterministicBlock, error) {
+	switch len(key) {
+	case 32, 48, 64:
+	default:
+		return nil, fmt.Errorf("invalid AES-SIV key size: %d", len(key))
+	}
+
+	cmacAES, err := aes.NewCipher(key[:len(key)/2])
+	if err != nil {
+		return nil, err
+	}
+
+	ctrAES, err := aes.NewCipher(key[len(key)/2:])
+	if err != nil {
+		return nil, err
+	}
+
+	return &sivBlock{
+		ctrAES: ctrAES,
+		macAES: cmacAES,
+	}, nil
+}
+
+// NewEncodedBlock returns a Block that wraps the provided Block, using the provided encoding for its string
+// functions. Data encrypted by the wrapped Block is not otherwise changed.
+func NewEncodedBlock(b Block, encoding Encoding) (Block, error) {
+	if b == nil {
+		return nil, errors.New("no block provided")
+	}
+
+	if encoding == nil {
+		return nil, errors.New("no encoding provided")
+	}
+
+	return &encodedBlock{
+		block:    b,
+		encoding: encoding,
+	}, nil
+}
+
+// NewExpiringBlock returns a Block that wraps the provided Block, sealing the time data was encrypted and the
+// provided time to live inside the authenticated data. Decrypting data whose time to live has passed fails with
+// ErrExpired. Data whose issued at time is more than a minute in the future, or that was not encrypted by an expiring
+// Block, fails with ErrAuthenticationFailed.
+func NewExpiringBlock(b Block, ttl time.Duration) (Block, error) {
+	return NewExpiringBlockWithClock(b, ttl, time.Now)
+}
+
+// NewExpiringBlockWithClock returns an expiring Block, as NewExpiringBlock does, that reads the current time from the
+// provided clock instead of time.Now. This allows expiry to be tested without waiting for time to live to pass.
+func NewExpiringBlockWithClock(b Block, ttl time.Duration, now func() time.Time) (Block, error) {
+	if b == nil {
+		return nil, errors.New("no block provided")
+	}
+
+	if ttl <= 0 {
+		return nil, errors.New("time to live must be greater than zero")
+	}
+
+	if now == nil {
+		return nil, errors.New("no clock provided")
+	}
+
+	return &expiringBlock{
+		block: b,
+		now:   now,
+		ttl:   ttl,
+	}, nil
+}
+
+// NewFileKEK returns a local KeyEncryptionKey using the AES key stored in the file at the provided path. The key is
+// loaded with LoadKeyFromFile, so it may be hex, base64, or PEM encoded, and the file must only be accessible by its
+// owner.
+func NewFileKEK(path string) (KeyEncryptionKey, error) {
+	key, err := LoadKeyFromFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewLocalKEK(key)
+}
+
+// NewKEKBlock returns a Block that performs envelope encryption. Each message is encrypted with a newly generated
+// AES-256 data key, which is wrapped by the provided KeyEncryptionKey and stored with the message. Rotating the key
+// encryption key only requires data keys to be re-wrapped, rather than every message to be re-encrypted.
+func NewKEKBlock(kek KeyEncryptionKey) (Block, error) {
+	if kek == nil {
+		return nil, errors.New("no key encryption key provided")
+	}
+
+	return &kekBlock{
+		kek: kek,
+	}, nil
+}
+
+// NewKeyDerivation returns a KeyDerivation that derives keys from the provided master key, which must be at least 16
+// bytes. The salt is optional, and should be a random, non-secret value when provided.
+func NewKeyDerivation(master []byte, salt []byte) (KeyDerivation, error) {
+	if len(master) < derivationMinMasterSize {
+		return nil, fmt.Errorf("master key must be at least %d bytes", derivationMinMasterSize)
+	}
+
+	return &keyDerivation{
+		master: bytes.Clone(master),
+		salt:   bytes.Clone(salt),
+	}, nil
+}
+
+// NewKeyring returns a Keyring using the provided key, identified by id, as the primary key.
+func NewKeyring(id uint32, key []byte) (Keyring, error) {
+	primary, err := newAESBlock(key, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return &keyring{
+		keys:    map[uint32]*block{id: primary},
+		primary: id,
+	}, nil
+}
+
+// NewLocalKEK returns a KeyEncryptionKey that wraps data keys with AES-GCM, using the provided AES key. This is
+// useful for testing, or when a key management service is not available.
+func NewLocalKEK(key []byte) (KeyEncryptionKey, error) {
+	kekBlock, err := NewBlock(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return &localKEK{
+		block: kekBlock,
+	}, nil
+}
+
+// NewPaddedBlock returns a Block that wraps the provided Block, padding data with the provided Padding before it is
+// encrypted so the length of the encrypted data does not reveal the exact length of the data. The padding is
+// authenticated along with the data and removed when it is decrypted.
+func NewPaddedBlock(b Block, padding Padding) (Block, error) {
+	if b == nil {
+		return nil, errors.New("no block provided")
+	}
+
+	if padding == nil {
+		return nil, errors.New("no padding provided")
+	}
+
+	return &paddedBlock{
+		block:   b,
+		padding: padding,
+	}, nil
+}
+
+// NewPassphraseBlock returns a Block that encrypts data with an AES-256 key derived from the provided passphrase, using
+// the argon2 function and parameters in the provided config. A new salt is generated for each message, and is stored,
+// along with the function and parameters, in a header in the encrypted data. Data can be decrypted with only the
+// passphrase, even if the config has since changed, as long as its memory, iterations, and threads do not exceed those
+// of the provided config. Data with more expensive parameters is rejected with ErrPassphraseCost before a key is
+// derived, so the parameters of the config should only ever be raised.
+//
+// The KeySize of the provided config is ignored, as AES-256 keys are always derived. SaltSize must be a multiple of 16
+// between 16 and 240 bytes.
+func NewPassphraseBlock(passphrase string, config hash.Config) (Block, error) {
+	if passphrase == "" {
+		return nil, errors.New("no passphrase provided")
+	}
+
+	if _, ok := passphraseFunctions[config.Function]; !ok {
+		return nil, fmt.Errorf("unsupported passphrase function: %s", config.Function)
+	}
+
+	if config.SaltSize < 16 || config.SaltSize > math.MaxUint8 {
+		return nil, fmt.Errorf("invalid passphrase salt size: %d", config.SaltSize)
+	}
+
+	config.KeySize = passphraseKeySize
+	if err := hash.ValidateKeyConfig(config); err != nil {
+		return nil, err
+	}
+
+	return &passphraseBlock{
+		config:     config,
+		passphrase: passphrase,
+	}, nil
+}
+
+// NewRecipientBlock returns a Block that encrypts data to one or more X25519 recipients, and decrypts data encrypted to
+// the provided identity. Either may be omitted if the Block is only used to encrypt, or only used to decrypt.
+//
+// Each message is encrypted with a new file key, which is wrapped separately for every recipient, so any one of them
+// can decrypt it with their own Identity.
+func NewRecipientBlock(identity Identity, recipients ...Recipient) (Block, error) {
+	if identity == nil && len(recipients) == 0 {
+		return nil, errors.New("no identity or recipients provided")
+	}
+
+	if len(recipients) > math.MaxUint16 {
+		return nil, fmt.Errorf("too many recipients: %d", len(recipients))
+	}
+
+	for _, recipient := range recipients {
+		if recipient == nil {
+			return nil, errors.New("nil recipient provided")
+		}
+	}
+
+	return &recipientBlock{
+		identity:   identity,
+		recipients: slices.Clone(recipients),
+	}, nil
+}
+
+// NewXChaChaBlock returns an XChaCha20-Poly1305 Block to be used with Encrypt and Decrypt functions. The provided key
+// must be 32 bytes.
+//
+// XChaCha20-Poly1305 uses random 192-bit nonces, which are safe to use for a very large number of messages, and
+// performs well on hardware without AES acceleration.
+func NewXChaChaBlock(key []byte) (Block, error) {
+	aead, err := chacha20poly1305.NewX(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return &block{
+		aead:      aead,
+		algorithm: AlgorithmXChaCha20Poly1305,
+	}, nil
+}
@@ -28,8 +28,14 @@ OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
 package crypt
 
 import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
 	"encoding/hex"
 	"testing"
+	"time"
+
+	"eljef.dev/go/auth/pkg/hash"
 )
 
 func TestNewBlock(t *testing.T) {
@@ -60,3 +66,542 @@ func TestNewBlock(t *testing.T) {
 		})
 	}
 }
+
+func TestNewKeyring(t *testing.T) {
+	tests := []struct {
+		name    string
+		key     string
+		wantErr bool
+	}{
+		{"bad", "9d9a7ae2334a", true},
+		{"good", "9c059f5890d780952375226e2526b6134d703e37076b1b8d8da36f1e12d73859", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key, err := hex.DecodeString(tt.key)
+			if err != nil {
+				t.Fatalf("NewKeyring() error = %v", err)
+			}
+
+			got, err := NewKeyring(7, key)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewKeyring() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if (got == nil) != tt.wantErr {
+				t.Errorf("NewKeyring() got = %v, wantNil = %v", got, tt.wantErr)
+				return
+			}
+			if got != nil && got.Primary() != 7 {
+				t.Errorf("NewKeyring() primary = %d, want 7", got.Primary())
+			}
+		})
+	}
+}
+
+type badCipherBlock struct{}
+
+func (b *badCipherBlock) BlockSize() int      { return 0 }
+func (b *badCipherBlock) Encrypt(_, _ []byte) {}
+func (b *badCipherBlock) Decrypt(_, _ []byte) {}
+
+func Test_newGCMBlock(t *testing.T) {
+	rAES, err := aes.NewCipher([]byte("testKeySixteen16"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		cipher  cipher.Block
+		wantErr bool
+	}{
+		{"NewGCM error", &badCipherBlock{}, true},
+		{"good", rAES, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := newGCMBlock(tt.cipher, 0)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("newGCMBlock() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if (got == nil) != tt.wantErr {
+				t.Errorf("newGCMBlock() got = %v, wantNil = %v", got, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_decodeString(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		want    []byte
+		wantErr bool
+	}{
+		{"no data", "", nil, true},
+		{"bad data", "not hex", nil, true},
+		{"good", "74657374", []byte("test"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := decodeString(tt.data, EncodingHex)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("decodeString() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !bytes.Equal(got, tt.want) {
+				t.Errorf("decodeString() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewXChaChaBlock(t *testing.T) {
+	tests := []struct {
+		name    string
+		key     string
+		wantErr bool
+	}{
+		{"bad", "9d9a7ae2334a", true},
+		{"aes-128 sized key", "9c059f5890d780952375226e2526b613", true},
+		{"good", "9c059f5890d780952375226e2526b6134d703e37076b1b8d8da36f1e12d73859", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key, err := hex.DecodeString(tt.key)
+			if err != nil {
+				t.Fatalf("NewXChaChaBlock() error = %v", err)
+			}
+
+			got, err := NewXChaChaBlock(key)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewXChaChaBlock() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if (got == nil) != tt.wantErr {
+				t.Errorf("NewXChaChaBlock() got = %v, wantNil = %v", got, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNewDecryptReader(t *testing.T) {
+	tests := []struct {
+		name    string
+		key     string
+		data    []byte
+		wantErr bool
+	}{
+		{"no header", "testKeySixteen16", []byte{'E', 'J', 'S'}, true},
+		{"bad magic", "testKeySixteen16", bytes.Repeat([]byte{1}, streamHeaderSize), true},
+		{"bad version", "testKeySixteen16", append([]byte{'E', 'J', 'S', 9}, make([]byte, streamSaltSize)...), true},
+		{"bad key", "9d9a7ae2334a", append([]byte{'E', 'J', 'S', 1}, make([]byte, streamSaltSize)...), true},
+		{"good", "testKeySixteen16", append([]byte{'E', 'J', 'S', 1}, make([]byte, streamSaltSize)...), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewDecryptReader(bytes.NewReader(tt.data), []byte(tt.key))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewDecryptReader() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if (got == nil) != tt.wantErr {
+				t.Errorf("NewDecryptReader() got = %v, wantNil = %v", got, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNewEncryptWriter(t *testing.T) {
+	tests := []struct {
+		name    string
+		key     string
+		wantErr bool
+	}{
+		{"bad key", "9d9a7ae2334a", true},
+		{"good", "testKeySixteen16", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := &bytes.Buffer{}
+
+			got, err := NewEncryptWriter(out, []byte(tt.key))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewEncryptWriter() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if (got == nil) != tt.wantErr {
+				t.Errorf("NewEncryptWriter() got = %v, wantNil = %v", got, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && out.Len() != streamHeaderSize {
+				t.Errorf("NewEncryptWriter() header size = %d, want %d", out.Len(), streamHeaderSize)
+			}
+		})
+	}
+}
+
+func TestNewExpiringBlock(t *testing.T) {
+	inner, err := NewBlock([]byte("testKeySixteen16"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		block   Block
+		ttl     time.Duration
+		wantErr bool
+	}{
+		{"no block", nil, time.Minute, true},
+		{"zero ttl", inner, 0, true},
+		{"negative ttl", inner, -time.Minute, true},
+		{"good", inner, time.Minute, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewExpiringBlock(tt.block, tt.ttl)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewExpiringBlock() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if (got == nil) != tt.wantErr {
+				t.Errorf("NewExpiringBlock() got = %v, wantNil = %v", got, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNewExpiringBlockWithClock(t *testing.T) {
+	inner, err := NewBlock([]byte("testKeySixteen16"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		block   Block
+		ttl     time.Duration
+		now     func() time.Time
+		wantErr bool
+	}{
+		{"no block", nil, time.Minute, time.Now, true},
+		{"zero ttl", inner, 0, time.Now, true},
+		{"no clock", inner, time.Minute, nil, true},
+		{"good", inner, time.Minute, time.Now, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewExpiringBlockWithClock(tt.block, tt.ttl, tt.now)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewExpiringBlockWithClock() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if (got == nil) != tt.wantErr {
+				t.Errorf("NewExpiringBlockWithClock() got = %v, wantNil = %v", got, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNewBlockWithEncoding(t *testing.T) {
+	tests := []struct {
+		name     string
+		key      string
+		encoding Encoding
+		wantErr  bool
+	}{
+		{"bad key", "9d9a7ae2334a", EncodingBase64URL, true},
+		{"no encoding", "testKeySixteen16", nil, true},
+		{"good", "testKeySixteen16", EncodingBase64URL, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewBlockWithEncoding([]byte(tt.key), tt.encoding)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewBlockWithEncoding() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if (got == nil) != tt.wantErr {
+				t.Errorf("NewBlockWithEncoding() got = %v, wantNil = %v", got, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNewEncodedBlock(t *testing.T) {
+	inner, err := NewBlock([]byte("testKeySixteen16"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		block    Block
+		encoding Encoding
+		wantErr  bool
+	}{
+		{"no block", nil, EncodingBase64, true},
+		{"no encoding", inner, nil, true},
+		{"good", inner, EncodingBase64, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewEncodedBlock(tt.block, tt.encoding)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewEncodedBlock() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if (got == nil) != tt.wantErr {
+				t.Errorf("NewEncodedBlock() got = %v, wantNil = %v", got, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNewKEKBlock(t *testing.T) {
+	kek, err := NewLocalKEK([]byte("testKeySixteen16"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		kek     KeyEncryptionKey
+		wantErr bool
+	}{
+		{"no kek", nil, true},
+		{"good", kek, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewKEKBlock(tt.kek)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewKEKBlock() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if (got == nil) != tt.wantErr {
+				t.Errorf("NewKEKBlock() got = %v, wantNil = %v", got, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNewLocalKEK(t *testing.T) {
+	tests := []struct {
+		name    string
+		key     string
+		wantErr bool
+	}{
+		{"bad", "9d9a7ae2334a", true},
+		{"good", "testKeySixteen16", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewLocalKEK([]byte(tt.key))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewLocalKEK() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if (got == nil) != tt.wantErr {
+				t.Errorf("NewLocalKEK() got = %v, wantNil = %v", got, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNewPassphraseBlock(t *testing.T) {
+	badFunction := hash.GetConfigDefaults()
+	badFunction.Function = "unknown"
+	smallSalt := hash.GetConfigDefaults()
+	smallSalt.SaltSize = 8
+	largeSalt := hash.GetConfigDefaults()
+	largeSalt.SaltSize = 256
+	unevenSalt := hash.GetConfigDefaults()
+	unevenSalt.SaltSize = 17
+	noIterations := hash.GetConfigDefaults()
+	noIterations.Iterations = 0
+
+	tests := []struct {
+		name       string
+		passphrase string
+		config     hash.Config
+		wantErr    bool
+	}{
+		{"no passphrase", "", hash.GetConfigDefaults(), true},
+		{"bad function", "correct horse", badFunction, true},
+		{"small salt", "correct horse", smallSalt, true},
+		{"large salt", "correct horse", largeSalt, true},
+		{"uneven salt", "correct horse", unevenSalt, true},
+		{"no iterations", "correct horse", noIterations, true},
+		{"good", "correct horse", hash.GetConfigDefaults(), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewPassphraseBlock(tt.passphrase, tt.config)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewPassphraseBlock() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if (got == nil) != tt.wantErr {
+				t.Errorf("NewPassphraseBlock() got = %v, wantNil = %v", got, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNewKeyDerivation(t *testing.T) {
+	tests := []struct {
+		name    string
+		master  string
+		wantErr bool
+	}{
+		{"short master", "short", true},
+		{"good", "testKeySixteen16", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewKeyDerivation([]byte(tt.master), nil)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewKeyDerivation() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if (got == nil) != tt.wantErr {
+				t.Errorf("NewKeyDerivation() got = %v, wantNil = %v", got, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNewDeterministicBlock(t *testing.T) {
+	tests := []struct {
+		name    string
+		size    int
+		wantErr bool
+	}{
+		{"too small", 16, true},
+		{"odd size", 40, true},
+		{"aes-128", 32, false},
+		{"aes-192", 48, false},
+		{"aes-256", 64, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewDeterministicBlock(make([]byte, tt.size))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewDeterministicBlock() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if (got == nil) != tt.wantErr {
+				t.Errorf("NewDeterministicBlock() got = %v, wantNil = %v", got, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNewRecipientBlock(t *testing.T) {
+	identity, err := GenerateIdentity()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		identity   Identity
+		recipients []Recipient
+		wantErr    bool
+	}{
+		{"nothing", nil, nil, true},
+		{"nil recipient", nil, []Recipient{nil}, true},
+		{"identity", identity, nil, false},
+		{"recipients", nil, []Recipient{identity.Recipient()}, false},
+		{"both", identity, []Recipient{identity.Recipient()}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewRecipientBlock(tt.identity, tt.recipients...)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewRecipientBlock() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if (got == nil) != tt.wantErr {
+				t.Errorf("NewRecipientBlock() got = %v, wantNil = %v", got, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNewBucketPadding(t *testing.T) {
+	tests := []struct {
+		name    string
+		sizes   []int
+		wantErr bool
+	}{
+		{"no sizes", nil, true},
+		{"zero size", []int{0, 64}, true},
+		{"negative size", []int{64, -1}, true},
+		{"good", []int{64, 128}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewBucketPadding(tt.sizes...)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewBucketPadding() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if (got == nil) != tt.wantErr {
+				t.Errorf("NewBucketPadding() got = %v, wantNil = %v", got, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNewPaddedBlock(t *testing.T) {
+	b, err := NewBlock([]byte("testKeyThirtyTwoBytesLong32Bytes"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		block   Block
+		padding Padding
+		wantErr bool
+	}{
+		{"no block", nil, PaddingPadme, true},
+		{"no padding", b, nil, true},
+		{"good", b, PaddingPadme, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewPaddedBlock(tt.block, tt.padding)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewPaddedBlock() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if (got == nil) != tt.wantErr {
+				t.Errorf("NewPaddedBlock() got = %v, wantNil = %v", got, tt.wantErr)
+			}
+		})
+	}
+}
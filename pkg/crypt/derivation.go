@@ -0,0 +1,71 @@
+/* SPDX-License-Identifier: BSD-2-Clause
+
+Copyright (c) 2020-2026, Jef Oliver
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without modification,
+are permitted provided that the following conditions are met:
+
+  1. Redistributions of source code must retain the above copyright notice, this
+     list of conditions and the following disclaimer.
+
+  2. Redistributions in binary form must reproduce the above copyright notice
+     this list of conditions and the following disclaimer in the documentation
+     and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package crypt
+
+import (
+	"crypto/hkdf"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+)
+
+const (
+	// derivationMaxKeySize is the largest key, in bytes, HKDF-SHA256 can derive
+	derivationMaxKeySize = 255 * sha256.Size
+	// derivationMinMasterSize is the smallest master key, in bytes, accepted for key derivation
+	derivationMinMasterSize = 16
+)
+
+type keyDerivation struct {
+	master []byte
+	salt   []byte
+}
+
+// Block returns an AES-256-GCM Block using the key derived for the provided label
+func (k *keyDerivation) Block(label string) (Block, error) {
+	key, err := k.DeriveKey(label, 32)
+	if err != nil {
+		return nil, err
+	}
+	defer clear(key)
+
+	return NewBlock(key)
+}
+
+// DeriveKey derives a key of the provided size for the provided label
+func (k *keyDerivation) DeriveKey(label string, size int) ([]byte, error) {
+	if label == "" {
+		return nil, errors.New("no label provided")
+	}
+
+	if size < 1 || size > derivationMaxKeySize {
+		return nil, fmt.Errorf("invalid derived key size: %d", size)
+	}
+
+	return hkdf.Key(sha256.New, k.master, k.salt, label, size)
+}
@@ -0,0 +1,135 @@
+/* SPDX-License-Identifier: BSD-2-Clause
+
+Copyright (c) 2020-2026, Jef Oliver
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without modification,
+are permitted provided that the following conditions are met:
+
+  1. Redistributions of source code must retain the above copyright notice, this
+     list of conditions and the following disclaimer.
+
+  2. Redistributions in binary form must reproduce the above copyright notice
+     this list of conditions and the following disclaimer in the documentation
+     and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package crypt
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"testing"
+)
+
+func Test_keyDerivationDeriveKey(t *testing.T) {
+	// test vectors from https://www.rfc-editor.org/rfc/rfc5869#appendix-A
+	tests := []struct {
+		name    string
+		master  string
+		salt    string
+		label   string
+		size    int
+		want    string
+		wantErr bool
+	}{
+		{"no label", "0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b", "", "", 32, "", true},
+		{"zero size", "0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b", "", "cookies", 0, "", true},
+		{"too large", "0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b", "", "cookies", 255*32 + 1, "", true},
+		{"rfc5869 test case 1", "0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b", "000102030405060708090a0b0c",
+			"\xf0\xf1\xf2\xf3\xf4\xf5\xf6\xf7\xf8\xf9", 42,
+			"3cb25f25faacd57a90434f64d0362f2a2d2d0a90cf1a5a4c5db02d56ecc4c5bf34007208d5b887185865", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			master, err := hex.DecodeString(tt.master)
+			if err != nil {
+				t.Fatal(err)
+			}
+			salt, err := hex.DecodeString(tt.salt)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			tester := &keyDerivation{master: master, salt: salt}
+
+			got, err := tester.DeriveKey(tt.label, tt.size)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("keyDerivation.DeriveKey() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if hex.EncodeToString(got) != tt.want {
+				t.Errorf("keyDerivation.DeriveKey() = %x, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+// nolint: gocognit
+func Test_keyDerivationBlock(t *testing.T) {
+	t.Run("labels are independent", func(t *testing.T) {
+		data := []byte("this is test data")
+
+		tester := &keyDerivation{master: []byte("this is a master key")}
+
+		cookieKey, err := tester.DeriveKey("cookies", 32)
+		if err != nil {
+			t.Fatalf("keyDerivation.DeriveKey() error = %v", err)
+		}
+		csrfKey, err := tester.DeriveKey("csrf", 32)
+		if err != nil {
+			t.Fatalf("keyDerivation.DeriveKey() error = %v", err)
+		}
+		if bytes.Equal(cookieKey, csrfKey) {
+			t.Error("keyDerivation.DeriveKey() derived the same key for different labels")
+		}
+
+		cookieBlock, err := tester.Block("cookies")
+		if err != nil {
+			t.Fatalf("keyDerivation.Block() error = %v", err)
+		}
+		csrfBlock, err := tester.Block("csrf")
+		if err != nil {
+			t.Fatalf("keyDerivation.Block() error = %v", err)
+		}
+
+		encryptedData, err := cookieBlock.Encrypt(data)
+		if err != nil {
+			t.Fatalf("block.Encrypt() error = %v", err)
+		}
+
+		if _, err = csrfBlock.Decrypt(encryptedData); !errors.Is(err, ErrAuthenticationFailed) {
+			t.Errorf("block.Decrypt() with other label error = %v, want %v", err, ErrAuthenticationFailed)
+		}
+
+		// derived keys are deterministic, so a Block for the same label can decrypt the data
+		sameBlock, err := tester.Block("cookies")
+		if err != nil {
+			t.Fatalf("keyDerivation.Block() error = %v", err)
+		}
+		got, err := sameBlock.Decrypt(encryptedData)
+		if err != nil {
+			t.Fatalf("block.Decrypt() error = %v", err)
+		}
+		if !bytes.Equal(got, data) {
+			t.Errorf("block.Decrypt() = %v, want %v", got, data)
+		}
+
+		if _, err = tester.Block(""); err == nil {
+			t.Error("keyDerivation.Block() no label error = nil, want error")
+		}
+	})
+}
@@ -0,0 +1,113 @@
+/* SPDX-License-Identifier: BSD-2-Clause
+
+Copyright (c) 2020-2026, Jef Oliver
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without modification,
+are permitted provided that the following conditions are met:
+
+  1. Redistributions of source code must retain the above copyright notice, this
+     list of conditions and the following disclaimer.
+
+  2. Redistributions in binary form must reproduce the above copyright notice
+     this list of conditions and the following disclaimer in the documentation
+     and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package crypt
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+)
+
+var (
+	// EncodingBase64 encodes encrypted data as padded, standard base64
+	EncodingBase64 Encoding = base64.StdEncoding
+	// EncodingBase64URL encodes encrypted data as unpadded, URL safe base64
+	EncodingBase64URL Encoding = base64.RawURLEncoding
+	// EncodingHex encodes encrypted data as hex. This is the default encoding.
+	EncodingHex Encoding = hexEncoding{}
+)
+
+type hexEncoding struct{}
+
+// DecodeString decodes a hex encoded string
+func (h hexEncoding) DecodeString(data string) ([]byte, error) {
+	return hex.DecodeString(data)
+}
+
+// EncodeToString encodes data to a hex encoded string
+func (h hexEncoding) EncodeToString(data []byte) string {
+	return hex.EncodeToString(data)
+}
+
+type encodedBlock struct {
+	block    Block
+	encoding Encoding
+}
+
+// Decrypt decrypts the provided data
+func (e *encodedBlock) Decrypt(data []byte) ([]byte, error) {
+	return e.block.Decrypt(data)
+}
+
+// DecryptFromString decrypts data stored in an encoded string
+func (e *encodedBlock) DecryptFromString(data string) ([]byte, error) {
+	return e.DecryptFromStringWithAAD(data, nil)
+}
+
+// DecryptFromStringWithAAD decrypts data stored in an encoded string, authenticating it against the provided
+// additional data
+func (e *encodedBlock) DecryptFromStringWithAAD(data string, aad []byte) ([]byte, error) {
+	dataToDecode, err := decodeString(data, e.encoding)
+	if err != nil {
+		return nil, err
+	}
+
+	return e.block.DecryptWithAAD(dataToDecode, aad)
+}
+
+// DecryptWithAAD decrypts the provided data, authenticating it against the provided additional data
+func (e *encodedBlock) DecryptWithAAD(data []byte, aad []byte) ([]byte, error) {
+	return e.block.DecryptWithAAD(data, aad)
+}
+
+// Encrypt encrypts the provided data
+func (e *encodedBlock) Encrypt(data []byte) ([]byte, error) {
+	return e.block.Encrypt(data)
+}
+
+// EncryptToString encrypts the provided data and returns it as an encoded string
+func (e *encodedBlock) EncryptToString(data []byte) (string, error) {
+	return e.EncryptToStringWithAAD(data, nil)
+}
+
+// EncryptToStringWithAAD encrypts the provided data, binding it to the provided additional data, and returns it as an
+// encoded string
+func (e *encodedBlock) EncryptToStringWithAAD(data []byte, aad []byte) (string, error) {
+	var ret string
+
+	encryptedData, err := e.block.EncryptWithAAD(data, aad)
+	if err == nil {
+		ret = e.encoding.EncodeToString(encryptedData)
+	}
+
+	return ret, err
+}
+
+// EncryptWithAAD encrypts the provided data, binding it to the provided additional data
+func (e *encodedBlock) EncryptWithAAD(data []byte, aad []byte) ([]byte, error) {
+	return e.block.EncryptWithAAD(data, aad)
+}
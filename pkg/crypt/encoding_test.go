@@ -0,0 +1,150 @@
+/* SPDX-License-Identifier: BSD-2-Clause
+
+Copyright (c) 2020-2026, Jef Oliver
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without modification,
+are permitted provided that the following conditions are met:
+
+  1. Redistributions of source code must retain the above copyright notice, this
+     list of conditions and the following disclaimer.
+
+  2. Redistributions in binary form must reproduce the above copyright notice
+     this list of conditions and the following disclaimer in the documentation
+     and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package crypt
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func Test_hexEncoding(t *testing.T) {
+	t.Run("round trip", func(t *testing.T) {
+		data := []byte("test")
+
+		got := EncodingHex.EncodeToString(data)
+		if got != "74657374" {
+			t.Errorf("hexEncoding.EncodeToString() = %s, want 74657374", got)
+		}
+
+		decoded, err := EncodingHex.DecodeString(got)
+		if err != nil {
+			t.Fatalf("hexEncoding.DecodeString() error = %v", err)
+		}
+		if !bytes.Equal(decoded, data) {
+			t.Errorf("hexEncoding.DecodeString() = %v, want %v", decoded, data)
+		}
+
+		if _, err = EncodingHex.DecodeString("not hex"); err == nil {
+			t.Error("hexEncoding.DecodeString() error = nil, want error")
+		}
+	})
+}
+
+// nolint: gocognit
+func Test_encodedBlockFullRun(t *testing.T) {
+	tests := []struct {
+		name     string
+		encoding Encoding
+		alphabet string
+		wantLen  int
+	}{
+		{"hex", EncodingHex, "0123456789abcdef", 108},
+		{"base64", EncodingBase64, "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789+/=", 72},
+		{"base64url", EncodingBase64URL, "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789-_", 72},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := []byte("this is test data")
+			aad := []byte("cookie:oauth_state")
+
+			tester, err := NewBlockWithEncoding([]byte("testKeySixteen16"), tt.encoding)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			encryptedData, err := tester.EncryptToStringWithAAD(data, aad)
+			if err != nil {
+				t.Fatalf("encodedBlock.EncryptToStringWithAAD() error = %v", err)
+			}
+			// 9 byte envelope header, 12 byte nonce, 17 bytes of data and a 16 byte tag
+			if len(encryptedData) != tt.wantLen {
+				t.Errorf("encodedBlock.EncryptToStringWithAAD() length = %d, want %d", len(encryptedData), tt.wantLen)
+			}
+			if strings.Trim(encryptedData, tt.alphabet) != "" {
+				t.Errorf("encodedBlock.EncryptToStringWithAAD() = %s, want only %s", encryptedData, tt.alphabet)
+			}
+
+			got, err := tester.DecryptFromStringWithAAD(encryptedData, aad)
+			if err != nil {
+				t.Fatalf("encodedBlock.DecryptFromStringWithAAD() error = %v", err)
+			}
+			if !bytes.Equal(got, data) {
+				t.Errorf("encodedBlock.DecryptFromStringWithAAD() = %v, want %v", got, data)
+			}
+
+			encryptedString, err := tester.EncryptToString(data)
+			if err != nil {
+				t.Fatalf("encodedBlock.EncryptToString() error = %v", err)
+			}
+			if got, err = tester.DecryptFromString(encryptedString); err != nil || !bytes.Equal(got, data) {
+				t.Errorf("encodedBlock.DecryptFromString() = %v, %v, want %v", got, err, data)
+			}
+
+			encryptedBytes, err := tester.EncryptWithAAD(data, aad)
+			if err != nil {
+				t.Fatalf("encodedBlock.EncryptWithAAD() error = %v", err)
+			}
+			if got, err = tester.DecryptWithAAD(encryptedBytes, aad); err != nil || !bytes.Equal(got, data) {
+				t.Errorf("encodedBlock.DecryptWithAAD() = %v, %v, want %v", got, err, data)
+			}
+
+			if encryptedBytes, err = tester.Encrypt(data); err != nil {
+				t.Fatalf("encodedBlock.Encrypt() error = %v", err)
+			}
+			if got, err = tester.Decrypt(encryptedBytes); err != nil || !bytes.Equal(got, data) {
+				t.Errorf("encodedBlock.Decrypt() = %v, %v, want %v", got, err, data)
+			}
+		})
+	}
+}
+
+func Test_encodedBlockDecryptFromString(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+	}{
+		{"no data", ""},
+		{"bad data", "not*base64"},
+		{"hex data", "62cee51629a72beee654a8fbc6d81ed07e981c15889c80baf636b73f22f95cc0e659dbeebe278792c256dd096f"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tester, err := NewBlockWithEncoding([]byte("testKeySixteen16"), EncodingBase64URL)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if _, err = tester.DecryptFromString(tt.data); err == nil {
+				t.Error("encodedBlock.DecryptFromString() error = nil, want error")
+			}
+		})
+	}
+}
@@ -0,0 +1,186 @@
+/* SPDX-License-Identifier: BSD-2-Clause
+
+Copyright (c) 2020-2026, Jef Oliver
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without modification,
+are permitted provided that the following conditions are met:
+
+  1. Redistributions of source code must retain the above copyright notice, this
+     list of conditions and the following disclaimer.
+
+  2. Redistributions in binary form must reproduce the above copyright notice
+     this list of conditions and the following disclaimer in the documentation
+     and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package crypt
+
+import (
+	"bytes"
+	"crypto/cipher"
+	"encoding/binary"
+	"fmt"
+)
+
+const (
+	// AlgorithmAESGCM identifies data encrypted with AES-GCM
+	AlgorithmAESGCM Algorithm = 1
+	// AlgorithmXChaCha20Poly1305 identifies data encrypted with XChaCha20-Poly1305
+	AlgorithmXChaCha20Poly1305 Algorithm = 2
+
+	// EnvelopeVersion is the current version of the envelope format
+	EnvelopeVersion uint8 = 1
+
+	// envelopeHeaderSize is the size, in bytes, of the magic, version, algorithm and key ID fields of an envelope
+	envelopeHeaderSize = 9
+)
+
+var (
+	// algorithmNonceSizes is the nonce size, in bytes, used by each supported algorithm
+	algorithmNonceSizes = map[Algorithm]int{
+		AlgorithmAESGCM:            12,
+		AlgorithmXChaCha20Poly1305: 24,
+	}
+
+	// envelopeMagic identifies the start of a framed envelope
+	envelopeMagic = []byte("EJC")
+)
+
+// Algorithm identifies the algorithm used to encrypt the data held in an Envelope.
+type Algorithm uint8
+
+// Envelope is a versioned, self-describing frame holding encrypted data.
+//
+// Framed envelopes are laid out as:
+//
+//	magic (3 bytes) | version (1 byte) | algorithm (1 byte) | key ID (4 bytes) | nonce | ciphertext
+//
+// The size of the nonce is determined by the algorithm. The magic, version, algorithm and key ID are authenticated
+// along with the ciphertext.
+type Envelope struct {
+	Algorithm  Algorithm // Algorithm is the algorithm used to encrypt the data.
+	Ciphertext []byte    // Ciphertext is the encrypted data, including the authentication tag.
+	KeyID      uint32    // KeyID is the ID of the key used to encrypt the data.
+	Legacy     bool      // Legacy is true if the envelope was parsed from unframed nonce and ciphertext data.
+	Nonce      []byte    // Nonce is the nonce used to encrypt the data.
+	Version    uint8     // Version is the version of the envelope format. Legacy envelopes are version 0.
+}
+
+// Bytes returns the framed form of the envelope.
+func (e *Envelope) Bytes() []byte {
+	ret := make([]byte, 0, envelopeHeaderSize+len(e.Nonce)+len(e.Ciphertext))
+	ret = append(ret, e.header()...)
+	ret = append(ret, e.Nonce...)
+
+	return append(ret, e.Ciphertext...)
+}
+
+// header returns the magic, version, algorithm and key ID fields of a framed envelope
+func (e *Envelope) header() []byte {
+	ret := make([]byte, envelopeHeaderSize)
+	copy(ret, envelopeMagic)
+	ret[3] = e.Version
+	ret[4] = byte(e.Algorithm)
+	binary.BigEndian.PutUint32(ret[5:], e.KeyID)
+
+	return ret
+}
+
+// additionalData returns the additional data to authenticate for the envelope, which includes the envelope header
+// when the envelope is framed.
+func (e *Envelope) additionalData(aad []byte) []byte {
+	if e.Legacy {
+		return aad
+	}
+
+	return append(e.header(), aad...)
+}
+
+// newEnvelope returns a framed envelope for the provided algorithm and key ID
+func newEnvelope(algorithm Algorithm, keyID uint32) Envelope {
+	return Envelope{
+		Algorithm: algorithm,
+		KeyID:     keyID,
+		Version:   EnvelopeVersion,
+	}
+}
+
+// openEnvelope decrypts and authenticates the ciphertext held in an envelope
+func openEnvelope(aead cipher.AEAD, env *Envelope, aad []byte) ([]byte, error) {
+	if len(env.Ciphertext) < aead.Overhead() {
+		return nil, fmt.Errorf("%w: missing authentication tag", ErrCiphertextTooShort)
+	}
+
+	ret, err := aead.Open(nil, env.Nonce, env.Ciphertext, env.additionalData(aad))
+	if err != nil {
+		return nil, ErrAuthenticationFailed
+	}
+
+	return ret, nil
+}
+
+// ParseEnvelope parses encrypted data into an Envelope.
+//
+// Data that does not start with the envelope magic is parsed as legacy, unframed, AES-GCM nonce and ciphertext data,
+// allowing data encrypted before envelopes were introduced to be decrypted.
+func ParseEnvelope(data []byte) (Envelope, error) {
+	if !bytes.HasPrefix(data, envelopeMagic) {
+		return parseLegacyEnvelope(data)
+	}
+
+	if len(data) < envelopeHeaderSize {
+		return Envelope{}, fmt.Errorf("%w: missing envelope header", ErrCiphertextTooShort)
+	}
+
+	ret := Envelope{
+		Algorithm: Algorithm(data[4]),
+		KeyID:     binary.BigEndian.Uint32(data[5:envelopeHeaderSize]),
+		Version:   data[3],
+	}
+
+	if ret.Version != EnvelopeVersion {
+		return Envelope{}, fmt.Errorf("%w: unsupported envelope version: %d", ErrAuthenticationFailed, ret.Version)
+	}
+
+	nonceSize, ok := algorithmNonceSizes[ret.Algorithm]
+	if !ok {
+		return Envelope{}, fmt.Errorf("%w: unsupported envelope algorithm: %d", ErrAuthenticationFailed,
+			ret.Algorithm)
+	}
+
+	if len(data) < envelopeHeaderSize+nonceSize {
+		return Envelope{}, fmt.Errorf("%w: missing nonce", ErrCiphertextTooShort)
+	}
+
+	ret.Nonce = data[envelopeHeaderSize : envelopeHeaderSize+nonceSize]
+	ret.Ciphertext = data[envelopeHeaderSize+nonceSize:]
+
+	return ret, nil
+}
+
+// parseLegacyEnvelope parses unframed AES-GCM nonce and ciphertext data into an Envelope
+func parseLegacyEnvelope(data []byte) (Envelope, error) {
+	nonceSize := algorithmNonceSizes[AlgorithmAESGCM]
+	if len(data) < nonceSize {
+		return Envelope{}, fmt.Errorf("%w: missing nonce", ErrCiphertextTooShort)
+	}
+
+	return Envelope{
+		Algorithm:  AlgorithmAESGCM,
+		Ciphertext: data[nonceSize:],
+		Legacy:     true,
+		Nonce:      data[:nonceSize],
+	}, nil
+}
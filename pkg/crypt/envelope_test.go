@@ -0,0 +1,131 @@
+/* SPDX-License-Identifier: BSD-2-Clause
+
+Copyright (c) 2020-2026, Jef Oliver
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without modification,
+are permitted provided that the following conditions are met:
+
+  1. Redistributions of source code must retain the above copyright notice, this
+     list of conditions and the following disclaimer.
+
+  2. Redistributions in binary form must reproduce the above copyright notice
+     this list of conditions and the following disclaimer in the documentation
+     and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package crypt
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestParseEnvelope(t *testing.T) {
+	nonce := []byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12}
+
+	tests := []struct {
+		name    string
+		data    []byte
+		want    Envelope
+		wantErr bool
+	}{
+		{"legacy too short", []byte{1, 2, 3}, Envelope{}, true},
+		{"legacy", append(append([]byte{}, nonce...), 99, 98),
+			Envelope{Algorithm: AlgorithmAESGCM, Ciphertext: []byte{99, 98}, Legacy: true, Nonce: nonce}, false},
+		{"header too short", []byte{'E', 'J', 'C', 1, 1}, Envelope{}, true},
+		{"bad version", []byte{'E', 'J', 'C', 9, 1, 0, 0, 0, 1}, Envelope{}, true},
+		{"bad algorithm", []byte{'E', 'J', 'C', 1, 99, 0, 0, 0, 1}, Envelope{}, true},
+		{"nonce too short", []byte{'E', 'J', 'C', 1, 1, 0, 0, 0, 1, 1, 2, 3}, Envelope{}, true},
+		{"good", append(append([]byte{'E', 'J', 'C', 1, 1, 0, 0, 1, 2}, nonce...), 99, 98),
+			Envelope{Algorithm: AlgorithmAESGCM, Ciphertext: []byte{99, 98}, KeyID: 258, Nonce: nonce, Version: 1}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseEnvelope(tt.data)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseEnvelope() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseEnvelope() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_envelopeBytes(t *testing.T) {
+	t.Run("good", func(t *testing.T) {
+		env := newEnvelope(AlgorithmAESGCM, 258)
+		env.Nonce = []byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12}
+		env.Ciphertext = []byte{99, 98}
+
+		want := []byte{'E', 'J', 'C', 1, 1, 0, 0, 1, 2, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 99, 98}
+		got := env.Bytes()
+		if !bytes.Equal(got, want) {
+			t.Errorf("Envelope.Bytes() = %v, want %v", got, want)
+			return
+		}
+
+		parsed, err := ParseEnvelope(got)
+		if err != nil {
+			t.Errorf("ParseEnvelope() error = %v", err)
+			return
+		}
+		if !reflect.DeepEqual(parsed, env) {
+			t.Errorf("ParseEnvelope() = %v, want %v", parsed, env)
+		}
+	})
+}
+
+func Test_envelopeAdditionalData(t *testing.T) {
+	tests := []struct {
+		name string
+		env  Envelope
+		aad  []byte
+		want []byte
+	}{
+		{"legacy", Envelope{Legacy: true}, []byte("aad"), []byte("aad")},
+		{"framed", newEnvelope(AlgorithmAESGCM, 1), []byte("aad"),
+			[]byte{'E', 'J', 'C', 1, 1, 0, 0, 0, 1, 'a', 'a', 'd'}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.env.additionalData(tt.aad); !bytes.Equal(got, tt.want) {
+				t.Errorf("Envelope.additionalData() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func FuzzParseEnvelope(f *testing.F) {
+	f.Add([]byte{})
+	f.Add([]byte{'E', 'J', 'C'})
+	f.Add([]byte{'E', 'J', 'C', 1, 2, 0, 0, 0, 1, 1, 2, 3})
+	f.Add(append([]byte{'E', 'J', 'C', 1, 1, 0, 0, 1, 2}, make([]byte, 30)...))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		env, err := ParseEnvelope(data)
+		if err != nil {
+			return
+		}
+
+		if !env.Legacy && !bytes.Equal(env.Bytes(), data) {
+			t.Errorf("Envelope.Bytes() = %v, want %v", env.Bytes(), data)
+		}
+	})
+}
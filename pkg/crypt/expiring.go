@@ -0,0 +1,165 @@
+/* SPDX-License-Identifier: BSD-2-Clause
+
+Copyright (c) 2020-2026, Jef Oliver
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without modification,
+are permitted provided that the following conditions are met:
+
+  1. Redistributions of source code must retain the above copyright notice, this
+     list of conditions and the following disclaimer.
+
+  2. Redistributions in binary form must reproduce the above copyright notice
+     this list of conditions and the following disclaimer in the documentation
+     and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package crypt
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"time"
+)
+
+const (
+	// ExpiringVersion is the current version of the expiring data format
+	ExpiringVersion uint8 = 1
+
+	// expiringClockSkew is how far in the future the issued at time of expiring data may be, allowing for clocks that
+	// differ between the systems encrypting and decrypting it
+	expiringClockSkew = time.Minute
+	// expiringHeaderSize is the size, in bytes, of the magic, version, issued at time and time to live sealed with
+	// expiring data
+	expiringHeaderSize = 20
+)
+
+var (
+	// ErrExpired is returned when decrypting data whose time to live has passed
+	ErrExpired = errors.New("encrypted data has expired")
+
+	// expiringMagic identifies the start of expiring data, once decrypted
+	expiringMagic = []byte("EJE")
+)
+
+// Data sealed by an expiringBlock is laid out as:
+//
+//	magic (3 bytes) | version (1 byte) | issued at (8 bytes) | time to live (8 bytes) | data
+//
+// The whole of it is encrypted by the wrapped Block, so the issued at time and time to live are authenticated along
+// with the data. The magic keeps data encrypted with the same key by other means from being opened as expiring data.
+
+type expiringBlock struct {
+	block Block
+	now   func() time.Time
+	ttl   time.Duration
+}
+
+// Decrypt decrypts the provided data, failing with ErrExpired if its time to live has passed
+func (e *expiringBlock) Decrypt(data []byte) ([]byte, error) {
+	return e.DecryptWithAAD(data, nil)
+}
+
+// DecryptFromString decrypts data stored in an encoded string, failing with ErrExpired if its time to live has passed
+func (e *expiringBlock) DecryptFromString(data string) ([]byte, error) {
+	return e.DecryptFromStringWithAAD(data, nil)
+}
+
+// DecryptFromStringWithAAD decrypts data stored in an encoded string, authenticating it against the provided
+// additional data, failing with ErrExpired if its time to live has passed
+func (e *expiringBlock) DecryptFromStringWithAAD(data string, aad []byte) ([]byte, error) {
+	return e.open(e.block.DecryptFromStringWithAAD(data, aad))
+}
+
+// DecryptWithAAD decrypts the provided data, authenticating it against the provided additional data, failing with
+// ErrExpired if its time to live has passed
+func (e *expiringBlock) DecryptWithAAD(data []byte, aad []byte) ([]byte, error) {
+	return e.open(e.block.DecryptWithAAD(data, aad))
+}
+
+// Encrypt encrypts the provided data, sealing the current time and time to live with it
+func (e *expiringBlock) Encrypt(data []byte) ([]byte, error) {
+	return e.EncryptWithAAD(data, nil)
+}
+
+// EncryptToString encrypts the provided data, sealing the current time and time to live with it, and returns it as
+// an encoded string
+func (e *expiringBlock) EncryptToString(data []byte) (string, error) {
+	return e.EncryptToStringWithAAD(data, nil)
+}
+
+// EncryptToStringWithAAD encrypts the provided data, sealing the current time and time to live with it and binding
+// it to the provided additional data, and returns it as an encoded string
+func (e *expiringBlock) EncryptToStringWithAAD(data []byte, aad []byte) (string, error) {
+	if data == nil {
+		return "", ErrNoData
+	}
+
+	return e.block.EncryptToStringWithAAD(e.seal(data), aad)
+}
+
+// EncryptWithAAD encrypts the provided data, sealing the current time and time to live with it and binding it to the
+// provided additional data
+func (e *expiringBlock) EncryptWithAAD(data []byte, aad []byte) ([]byte, error) {
+	if data == nil {
+		return nil, ErrNoData
+	}
+
+	return e.block.EncryptWithAAD(e.seal(data), aad)
+}
+
+// seal prepends the magic, version, current time and the time to live to the provided data
+func (e *expiringBlock) seal(data []byte) []byte {
+	ret := make([]byte, expiringHeaderSize, expiringHeaderSize+len(data))
+	copy(ret, expiringMagic)
+	ret[3] = ExpiringVersion
+	binary.BigEndian.PutUint64(ret[4:12], uint64(e.now().UnixNano()))
+	binary.BigEndian.PutUint64(ret[12:expiringHeaderSize], uint64(e.ttl))
+
+	return append(ret, data...)
+}
+
+// open checks the time to live of decrypted data, returning the data without its header if it has not expired
+func (e *expiringBlock) open(data []byte, err error) ([]byte, error) {
+	if err != nil {
+		return nil, err
+	}
+
+	if len(data) < expiringHeaderSize {
+		return nil, fmt.Errorf("%w: missing expiry", ErrCiphertextTooShort)
+	}
+
+	if !bytes.HasPrefix(data, expiringMagic) || data[3] != ExpiringVersion {
+		return nil, fmt.Errorf("%w: data not encrypted as expiring data", ErrAuthenticationFailed)
+	}
+
+	// Both values were written by seal from an int64, so the conversions back cannot overflow.
+	/* #nosec */
+	issuedAt := time.Unix(0, int64(binary.BigEndian.Uint64(data[4:12])))
+	/* #nosec */
+	ttl := time.Duration(binary.BigEndian.Uint64(data[12:expiringHeaderSize]))
+
+	now := e.now()
+	if issuedAt.After(now.Add(expiringClockSkew)) {
+		return nil, fmt.Errorf("%w: issued at time is in the future", ErrAuthenticationFailed)
+	}
+
+	if ttl <= 0 || now.After(issuedAt.Add(ttl)) {
+		return nil, ErrExpired
+	}
+
+	return data[expiringHeaderSize:], nil
+}
@@ -0,0 +1,184 @@
+/* SPDX-License-Identifier: BSD-2-Clause
+
+Copyright (c) 2020-2026, Jef Oliver
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without modification,
+are permitted provided that the following conditions are met:
+
+  1. Redistributions of source code must retain the above copyright notice, this
+     list of conditions and the following disclaimer.
+
+  2. Redistributions in binary form must reproduce the above copyright notice
+     this list of conditions and the following disclaimer in the documentation
+     and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package crypt
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"testing"
+	"time"
+)
+
+// nolint: gocognit
+func Test_expiringBlockFullRun(t *testing.T) {
+	issued := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		decryptTime time.Time
+		wantErr     error
+	}{
+		{"fresh", issued, nil},
+		{"just before expiry", issued.Add(5 * time.Minute), nil},
+		{"expired", issued.Add(5*time.Minute + time.Nanosecond), ErrExpired},
+		{"within clock skew", issued.Add(-time.Minute), nil},
+		{"issued in the future", issued.Add(-time.Minute - time.Nanosecond), ErrAuthenticationFailed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now := issued
+			data := []byte("this is test data")
+			aad := []byte("cookie:oauth_state")
+
+			inner, err := NewBlock([]byte("testKeySixteen16"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			tester, err := NewExpiringBlockWithClock(inner, 5*time.Minute, func() time.Time { return now })
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			encryptedData, err := tester.Encrypt(data)
+			if err != nil {
+				t.Fatalf("expiringBlock.Encrypt() error = %v", err)
+			}
+			encryptedString, err := tester.EncryptToStringWithAAD(data, aad)
+			if err != nil {
+				t.Fatalf("expiringBlock.EncryptToStringWithAAD() error = %v", err)
+			}
+
+			now = tt.decryptTime
+
+			got, err := tester.Decrypt(encryptedData)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("expiringBlock.Decrypt() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr == nil && !bytes.Equal(got, data) {
+				t.Errorf("expiringBlock.Decrypt() = %v, want %v", got, data)
+				return
+			}
+
+			got, err = tester.DecryptFromStringWithAAD(encryptedString, aad)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("expiringBlock.DecryptFromStringWithAAD() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr == nil && !bytes.Equal(got, data) {
+				t.Errorf("expiringBlock.DecryptFromStringWithAAD() = %v, want %v", got, data)
+			}
+		})
+	}
+}
+
+func Test_expiringBlockEncrypt(t *testing.T) {
+	t.Run("no data", func(t *testing.T) {
+		inner, err := NewBlock([]byte("testKeySixteen16"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		tester := &expiringBlock{block: inner, now: time.Now, ttl: time.Minute}
+
+		if _, err = tester.Encrypt(nil); err == nil {
+			t.Error("expiringBlock.Encrypt() error = nil, want error")
+		}
+		if _, err = tester.EncryptToString(nil); err == nil {
+			t.Error("expiringBlock.EncryptToString() error = nil, want error")
+		}
+	})
+}
+
+func Test_expiringBlockSharedKey(t *testing.T) {
+	inner, err := NewBlock([]byte("testKeySixteen16"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tester, err := NewExpiringBlock(inner, 5*time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// data encrypted with the same key, but not by the expiring block, whose first bytes would otherwise read as an
+	// issued at time and a time to live that never pass
+	forged := append(bytes.Repeat([]byte{0x7f}, expiringHeaderSize), 't')
+	encryptedData, err := inner.Encrypt(forged)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = tester.Decrypt(encryptedData); !errors.Is(err, ErrAuthenticationFailed) {
+		t.Errorf("expiringBlock.Decrypt() error = %v, want %v", err, ErrAuthenticationFailed)
+	}
+}
+
+func Test_expiringBlockOpen(t *testing.T) {
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	tester := &expiringBlock{now: func() time.Time { return now }, ttl: time.Minute}
+
+	// header returns an expiring data header with the provided magic, version, issued at time and time to live
+	header := func(magic string, version uint8, issuedAt time.Time, ttl time.Duration) []byte {
+		ret := append([]byte(magic), version)
+		ret = binary.BigEndian.AppendUint64(ret, uint64(issuedAt.UnixNano()))
+
+		return binary.BigEndian.AppendUint64(ret, uint64(ttl))
+	}
+
+	tests := []struct {
+		name    string
+		data    []byte
+		err     error
+		want    []byte
+		wantErr error
+	}{
+		{"decrypt error", nil, ErrNoData, nil, ErrNoData},
+		{"too short", []byte{1, 2, 3}, nil, nil, ErrCiphertextTooShort},
+		{"bad magic", append(header("EJX", 1, now, time.Minute), 't'), nil, nil, ErrAuthenticationFailed},
+		{"bad version", append(header("EJE", 9, now, time.Minute), 't'), nil, nil, ErrAuthenticationFailed},
+		{"future", append(header("EJE", 1, now.Add(time.Hour), time.Minute), 't'), nil, nil,
+			ErrAuthenticationFailed},
+		{"negative ttl", append(header("EJE", 1, now, -time.Minute), 't'), nil, nil, ErrExpired},
+		{"expired", append(header("EJE", 1, now.Add(-time.Hour), time.Minute), 't'), nil, nil, ErrExpired},
+		{"good", append(header("EJE", 1, now, time.Minute), 't'), nil, []byte("t"), nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tester.open(tt.data, tt.err)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("expiringBlock.open() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !bytes.Equal(got, tt.want) {
+				t.Errorf("expiringBlock.open() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,197 @@
+/* SPDX-License-Identifier: BSD-2-Clause
+
+Copyright (c) 2020-2026, Jef Oliver
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without modification,
+are permitted provided that the following conditions are met:
+
+  1. Redistributions of source code must retain the above copyright notice, this
+     list of conditions and the following disclaimer.
+
+  2. Redistributions in binary form must reproduce the above copyright notice
+     this list of conditions and the following disclaimer in the documentation
+     and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package crypt
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+)
+
+const (
+	// KEKVersion is the current version of the data key encryption format
+	KEKVersion uint8 = 1
+
+	// dataKeySize is the size, in bytes, of the AES-256 data keys generated for each message
+	dataKeySize = 32
+	// kekHeaderSize is the size, in bytes, of the magic, version and wrapped key length fields of data encrypted with
+	// a data key
+	kekHeaderSize = 6
+)
+
+// kekMagic identifies the start of data encrypted with a wrapped data key
+var kekMagic = []byte("EJK")
+
+// Data encrypted by a kekBlock is laid out as:
+//
+//	magic (3 bytes) | version (1 byte) | wrapped key length (2 bytes) | wrapped key | envelope
+//
+// The envelope holds the message, encrypted with AES-256-GCM using a data key generated for that message. The magic,
+// version and wrapped key are authenticated along with the message.
+
+type kekBlock struct {
+	kek KeyEncryptionKey
+}
+
+// Decrypt decrypts the provided data
+func (k *kekBlock) Decrypt(data []byte) ([]byte, error) {
+	return k.DecryptWithAAD(data, nil)
+}
+
+// DecryptFromString decrypts data stored in a hex encoded string
+func (k *kekBlock) DecryptFromString(data string) ([]byte, error) {
+	return k.DecryptFromStringWithAAD(data, nil)
+}
+
+// DecryptFromStringWithAAD decrypts data stored in a hex encoded string, authenticating it against the provided
+// additional data
+func (k *kekBlock) DecryptFromStringWithAAD(data string, aad []byte) ([]byte, error) {
+	dataToDecode, err := decodeString(data, EncodingHex)
+	if err != nil {
+		return nil, err
+	}
+
+	return k.DecryptWithAAD(dataToDecode, aad)
+}
+
+// DecryptWithAAD unwraps the data key held in the provided data and decrypts the data with it, authenticating it
+// against the provided additional data
+func (k *kekBlock) DecryptWithAAD(data []byte, aad []byte) ([]byte, error) {
+	if len(data) == 0 {
+		return nil, ErrNoData
+	}
+
+	if len(data) < kekHeaderSize {
+		return nil, fmt.Errorf("%w: missing data key header", ErrCiphertextTooShort)
+	}
+
+	if !bytes.HasPrefix(data, kekMagic) || data[3] != KEKVersion {
+		return nil, fmt.Errorf("%w: data not encrypted with a data key", ErrAuthenticationFailed)
+	}
+
+	headerSize := kekHeaderSize + int(binary.BigEndian.Uint16(data[4:kekHeaderSize]))
+	if len(data) <= headerSize {
+		return nil, fmt.Errorf("%w: missing wrapped data key or data", ErrCiphertextTooShort)
+	}
+
+	dataKey, err := k.kek.UnwrapKey(data[kekHeaderSize:headerSize])
+	if err != nil {
+		return nil, err
+	}
+	defer clear(dataKey)
+
+	dataBlock, err := newAESBlock(dataKey, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	return dataBlock.DecryptWithAAD(data[headerSize:], append(bytes.Clone(data[:headerSize]), aad...))
+}
+
+// Encrypt encrypts the provided data with a newly generated data key
+func (k *kekBlock) Encrypt(data []byte) ([]byte, error) {
+	return k.EncryptWithAAD(data, nil)
+}
+
+// EncryptToString encrypts the provided data with a newly generated data key and returns it as a hex encoded string
+func (k *kekBlock) EncryptToString(data []byte) (string, error) {
+	return k.EncryptToStringWithAAD(data, nil)
+}
+
+// EncryptToStringWithAAD encrypts the provided data with a newly generated data key, binding it to the provided
+// additional data, and returns it as a hex encoded string
+func (k *kekBlock) EncryptToStringWithAAD(data []byte, aad []byte) (string, error) {
+	var ret string
+
+	encryptedData, err := k.EncryptWithAAD(data, aad)
+	if err == nil {
+		ret = EncodingHex.EncodeToString(encryptedData)
+	}
+
+	return ret, err
+}
+
+// EncryptWithAAD encrypts the provided data with a newly generated data key, binding it to the provided additional
+// data, and prepends the data key wrapped by the key encryption key
+func (k *kekBlock) EncryptWithAAD(data []byte, aad []byte) ([]byte, error) {
+	if data == nil {
+		return nil, ErrNoData
+	}
+
+	dataKey := make([]byte, dataKeySize)
+	defer clear(dataKey)
+
+	if _, err := io.ReadFull(rand.Reader, dataKey); err != nil {
+		return nil, err
+	}
+
+	wrappedKey, err := k.kek.WrapKey(dataKey)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(wrappedKey) > math.MaxUint16 {
+		return nil, fmt.Errorf("wrapped data key too long: %d bytes", len(wrappedKey))
+	}
+
+	header := make([]byte, kekHeaderSize, kekHeaderSize+len(wrappedKey))
+	copy(header, kekMagic)
+	header[3] = KEKVersion
+	/* #nosec */
+	binary.BigEndian.PutUint16(header[4:], uint16(len(wrappedKey)))
+	header = append(header, wrappedKey...)
+
+	dataBlock, err := newAESBlock(dataKey, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	encryptedData, err := dataBlock.EncryptWithAAD(data, append(bytes.Clone(header), aad...))
+	if err != nil {
+		return nil, err
+	}
+
+	return append(header, encryptedData...), nil
+}
+
+type localKEK struct {
+	block Block
+}
+
+// UnwrapKey decrypts a data key wrapped by WrapKey
+func (l *localKEK) UnwrapKey(wrappedKey []byte) ([]byte, error) {
+	return l.block.Decrypt(wrappedKey)
+}
+
+// WrapKey encrypts a data key with the local key encryption key
+func (l *localKEK) WrapKey(dataKey []byte) ([]byte, error) {
+	return l.block.Encrypt(dataKey)
+}
@@ -0,0 +1,223 @@
+/* SPDX-License-Identifier: BSD-2-Clause
+
+Copyright (c) 2020-2026, Jef Oliver
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without modification,
+are permitted provided that the following conditions are met:
+
+  1. Redistributions of source code must retain the above copyright notice, this
+     list of conditions and the following disclaimer.
+
+  2. Redistributions in binary form must reproduce the above copyright notice
+     this list of conditions and the following disclaimer in the documentation
+     and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package crypt
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+type badKEK struct {
+	unwrapErr  bool
+	wrapErr    bool
+	wrappedLen int
+}
+
+func (b *badKEK) UnwrapKey(_ []byte) ([]byte, error) {
+	if b.unwrapErr {
+		return nil, errors.New("testing error")
+	}
+
+	return []byte("short key"), nil
+}
+
+func (b *badKEK) WrapKey(_ []byte) ([]byte, error) {
+	if b.wrapErr {
+		return nil, errors.New("testing error")
+	}
+
+	return make([]byte, b.wrappedLen), nil
+}
+
+// newTestKEKBlock returns a kekBlock using a local key encryption key
+func newTestKEKBlock(t *testing.T, key string) *kekBlock {
+	t.Helper()
+
+	kek, err := NewLocalKEK([]byte(key))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &kekBlock{kek: kek}
+}
+
+// nolint: gocognit
+func Test_kekBlockFullRun(t *testing.T) {
+	t.Run("kek block full run", func(t *testing.T) {
+		data := []byte("this is test data")
+		aad := []byte("record:1234")
+
+		tester := newTestKEKBlock(t, "testKeySixteen16")
+
+		first, err := tester.EncryptWithAAD(data, aad)
+		if err != nil {
+			t.Fatalf("kekBlock.EncryptWithAAD() error = %v", err)
+		}
+		second, err := tester.EncryptWithAAD(data, aad)
+		if err != nil {
+			t.Fatalf("kekBlock.EncryptWithAAD() error = %v", err)
+		}
+
+		wrappedLen := int(first[4])<<8 | int(first[5])
+		if bytes.Equal(first[:kekHeaderSize+wrappedLen], second[:kekHeaderSize+wrappedLen]) {
+			t.Error("kekBlock.EncryptWithAAD() reused a wrapped data key")
+		}
+
+		got, err := tester.DecryptWithAAD(first, aad)
+		if err != nil {
+			t.Fatalf("kekBlock.DecryptWithAAD() error = %v", err)
+		}
+		if !bytes.Equal(got, data) {
+			t.Errorf("kekBlock.DecryptWithAAD() = %v, want %v", got, data)
+		}
+
+		if _, err = tester.DecryptWithAAD(first, []byte("record:5678")); !errors.Is(err, ErrAuthenticationFailed) {
+			t.Errorf("kekBlock.DecryptWithAAD() different aad error = %v, want %v", err, ErrAuthenticationFailed)
+		}
+
+		encryptedString, err := tester.EncryptToString(data)
+		if err != nil {
+			t.Fatalf("kekBlock.EncryptToString() error = %v", err)
+		}
+		if got, err = tester.DecryptFromString(encryptedString); err != nil || !bytes.Equal(got, data) {
+			t.Errorf("kekBlock.DecryptFromString() = %v, %v, want %v", got, err, data)
+		}
+
+		other := newTestKEKBlock(t, "otherKeySixteen1")
+		if _, err = other.Decrypt(first); err == nil {
+			t.Error("kekBlock.Decrypt() with other kek error = nil, want error")
+		}
+	})
+}
+
+func Test_kekBlockDecryptWithAAD(t *testing.T) {
+	tests := []struct {
+		name    string
+		kek     KeyEncryptionKey
+		data    []byte
+		wantErr error
+	}{
+		{"no data", &badKEK{}, nil, ErrNoData},
+		{"short header", &badKEK{}, []byte{'E', 'J', 'K', 1}, ErrCiphertextTooShort},
+		{"bad magic", &badKEK{}, []byte{'E', 'J', 'X', 1, 0, 0, 1}, ErrAuthenticationFailed},
+		{"bad version", &badKEK{}, []byte{'E', 'J', 'K', 9, 0, 0, 1}, ErrAuthenticationFailed},
+		{"short wrapped key", &badKEK{}, []byte{'E', 'J', 'K', 1, 0, 9, 1}, ErrCiphertextTooShort},
+		{"no data after wrapped key", &badKEK{}, []byte{'E', 'J', 'K', 1, 0, 1, 1}, ErrCiphertextTooShort},
+		{"unwrap error", &badKEK{unwrapErr: true}, []byte{'E', 'J', 'K', 1, 0, 1, 1, 1}, nil},
+		{"bad data key", &badKEK{}, []byte{'E', 'J', 'K', 1, 0, 1, 1, 1}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tester := &kekBlock{kek: tt.kek}
+
+			got, err := tester.DecryptWithAAD(tt.data, nil)
+			if err == nil {
+				t.Errorf("kekBlock.DecryptWithAAD() = %v, want error", got)
+				return
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("kekBlock.DecryptWithAAD() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_kekBlockEncryptWithAAD(t *testing.T) {
+	tests := []struct {
+		name string
+		kek  KeyEncryptionKey
+		data []byte
+	}{
+		{"no data", &badKEK{}, nil},
+		{"wrap error", &badKEK{wrapErr: true}, []byte("data")},
+		{"wrapped key too long", &badKEK{wrappedLen: 1 << 16}, []byte("data")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tester := &kekBlock{kek: tt.kek}
+
+			if got, err := tester.EncryptWithAAD(tt.data, nil); err == nil {
+				t.Errorf("kekBlock.EncryptWithAAD() = %v, want error", got)
+			}
+		})
+	}
+}
+
+func Test_fileKEK(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		wantErr bool
+	}{
+		{"empty file", "", true},
+		{"not hex", "not a hex key", true},
+		{"bad key size", "9d9a7ae2334a\n", true},
+		{"good", "9c059f5890d780952375226e2526b6134d703e37076b1b8d8da36f1e12d73859\n", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "kek")
+			if err := os.WriteFile(path, []byte(tt.data), 0o600); err != nil {
+				t.Fatal(err)
+			}
+
+			kek, err := NewFileKEK(path)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewFileKEK() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+
+			wrapped, err := kek.WrapKey([]byte("this is a data key"))
+			if err != nil {
+				t.Fatalf("fileKEK.WrapKey() error = %v", err)
+			}
+			got, err := kek.UnwrapKey(wrapped)
+			if err != nil {
+				t.Fatalf("fileKEK.UnwrapKey() error = %v", err)
+			}
+			if string(got) != "this is a data key" {
+				t.Errorf("fileKEK.UnwrapKey() = %s, want \"this is a data key\"", got)
+			}
+		})
+	}
+
+	t.Run("missing file", func(t *testing.T) {
+		if _, err := NewFileKEK(filepath.Join(t.TempDir(), "missing")); err == nil {
+			t.Error("NewFileKEK() error = nil, want error")
+		}
+	})
+}
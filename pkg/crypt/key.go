@@ -0,0 +1,304 @@
+/* SPDX-License-Identifier: BSD-2-Clause
+
+Copyright (c) 2020-2026, Jef Oliver
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without modification,
+are permitted provided that the following conditions are met:
+
+  1. Redistributions of source code must retain the above copyright notice, this
+     list of conditions and the following disclaimer.
+
+  2. Redistributions in binary form must reproduce the above copyright notice
+     this list of conditions and the following disclaimer in the documentation
+     and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package crypt
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"slices"
+
+	"eljef.dev/go/auth/pkg/hash"
+	"golang.org/x/crypto/argon2"
+)
+
+const (
+	// EncryptedKeyMaxIterations is the largest number of Argon2 iterations accepted for an encrypted key
+	EncryptedKeyMaxIterations = 32
+	// EncryptedKeyMaxMemory is the largest amount of Argon2 memory, in KiB, accepted for an encrypted key
+	EncryptedKeyMaxMemory = 256 * 1024
+	// EncryptedKeyMaxThreads is the largest number of Argon2 threads accepted for an encrypted key
+	EncryptedKeyMaxThreads = 16
+
+	// pemTypeKey is the PEM block type used for plain keys
+	pemTypeKey = "EJ CRYPT KEY"
+	// pemTypeEncryptedKey is the PEM block type used for passphrase encrypted keys
+	pemTypeEncryptedKey = "EJ ENCRYPTED CRYPT KEY"
+	// keyFileMode is the file mode key files are written with
+	keyFileMode = 0o600
+)
+
+var (
+	// ErrInsecurePermissions is returned when loading a key file that is readable or writable by users other than its
+	// owner
+	ErrInsecurePermissions = errors.New("key file permissions are too open")
+
+	// ErrInvalidKeySize is returned when a key is not a size usable by this package
+	ErrInvalidKeySize = errors.New("invalid key size")
+
+	// ErrKeyEncrypted is returned when parsing an encrypted key without a passphrase
+	ErrKeyEncrypted = errors.New("key is encrypted")
+
+	// keySizes lists the key sizes, in bytes, accepted by the key utilities
+	keySizes = []int{16, 24, 32, 48, 64}
+)
+
+// GenerateKey returns a new random key of the provided size. 16, 24, and 32 byte keys are AES keys, while 48 and 64
+// byte keys are double length keys for NewDeterministicBlock.
+func GenerateKey(size int) ([]byte, error) {
+	if err := validateKeySize(size); err != nil {
+		return nil, err
+	}
+
+	key := make([]byte, size)
+	if _, err := io.ReadFull(rand.Reader, key); err != nil {
+		return nil, err
+	}
+
+	return key, nil
+}
+
+// LoadEncryptedKeyFromFile reads and decrypts a key file written by WriteEncryptedKeyFile. The key file must only be
+// accessible by its owner.
+func LoadEncryptedKeyFromFile(path, passphrase string) ([]byte, error) {
+	data, err := readKeyFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseEncryptedKey(data, passphrase)
+}
+
+// LoadKeyFromEnv reads a hex, base64, or PEM encoded key from the named environment variable
+func LoadKeyFromEnv(name string) ([]byte, error) {
+	data, ok := os.LookupEnv(name)
+	if !ok {
+		return nil, fmt.Errorf("environment variable not set: %s", name)
+	}
+
+	return ParseKey([]byte(data))
+}
+
+// LoadKeyFromFile reads a hex, base64, or PEM encoded key from the provided file. The key file must only be
+// accessible by its owner.
+func LoadKeyFromFile(path string) ([]byte, error) {
+	data, err := readKeyFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseKey(data)
+}
+
+// MarshalEncryptedKey encrypts the provided key with a passphrase, using the Argon2 parameters of the provided config,
+// and returns it PEM encoded. The memory, iterations, and threads of the config must not exceed EncryptedKeyMaxMemory,
+// EncryptedKeyMaxIterations, and EncryptedKeyMaxThreads, so the key can be read back by ParseEncryptedKey.
+func MarshalEncryptedKey(key []byte, passphrase string, config hash.Config) ([]byte, error) {
+	if err := validateKeySize(len(key)); err != nil {
+		return nil, err
+	}
+
+	if config.Memory > EncryptedKeyMaxMemory || config.Iterations > EncryptedKeyMaxIterations ||
+		config.Threads > EncryptedKeyMaxThreads {
+		return nil, fmt.Errorf("%w: memory %d, iterations %d, threads %d", ErrPassphraseCost, config.Memory,
+			config.Iterations, config.Threads)
+	}
+
+	keyBlock, err := NewPassphraseBlock(passphrase, config)
+	if err != nil {
+		return nil, err
+	}
+
+	encryptedKey, err := keyBlock.EncryptWithAAD(key, []byte(pemTypeEncryptedKey))
+	if err != nil {
+		return nil, err
+	}
+
+	return pem.EncodeToMemory(&pem.Block{Type: pemTypeEncryptedKey, Bytes: encryptedKey}), nil
+}
+
+// MarshalKey returns the provided key PEM encoded
+func MarshalKey(key []byte) ([]byte, error) {
+	if err := validateKeySize(len(key)); err != nil {
+		return nil, err
+	}
+
+	return pem.EncodeToMemory(&pem.Block{Type: pemTypeKey, Bytes: key}), nil
+}
+
+// ParseEncryptedKey decrypts a PEM encoded key produced by MarshalEncryptedKey. The parameters used to derive the key
+// are read from the encrypted key, and are limited to EncryptedKeyMaxMemory, EncryptedKeyMaxIterations, and
+// EncryptedKeyMaxThreads.
+func ParseEncryptedKey(data []byte, passphrase string) ([]byte, error) {
+	pemBlock, _ := pem.Decode(data)
+	if pemBlock == nil || pemBlock.Type != pemTypeEncryptedKey {
+		return nil, fmt.Errorf("%w: not an encrypted key", ErrInvalidEncoding)
+	}
+
+	// the parameters used to derive the key are read from the encrypted data, so the config only sets their limits
+	keyBlock, err := NewPassphraseBlock(passphrase, encryptedKeyMaxConfig())
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := keyBlock.DecryptWithAAD(pemBlock.Bytes, []byte(pemTypeEncryptedKey))
+	if err != nil {
+		return nil, err
+	}
+
+	if err = validateKeySize(len(key)); err != nil {
+		clear(key)
+		return nil, err
+	}
+
+	return key, nil
+}
+
+// encryptedKeyMaxConfig returns the config whose parameters limit those accepted by ParseEncryptedKey
+func encryptedKeyMaxConfig() hash.Config {
+	return hash.Config{
+		Function:   hash.Argon2ID,
+		Iterations: EncryptedKeyMaxIterations,
+		Memory:     EncryptedKeyMaxMemory,
+		SaltSize:   16,
+		Threads:    EncryptedKeyMaxThreads,
+		Version:    argon2.Version,
+	}
+}
+
+// ParseKey decodes a hex, base64, or PEM encoded key. Data that is valid hex for a key of an accepted size is always
+// treated as hex, as hex encoded keys are also valid base64.
+func ParseKey(data []byte) ([]byte, error) {
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) == 0 {
+		return nil, ErrNoData
+	}
+
+	if bytes.HasPrefix(trimmed, []byte("-----BEGIN ")) {
+		return parsePEMKey(trimmed)
+	}
+
+	encoded := string(trimmed)
+	for _, encoding := range []Encoding{EncodingHex, EncodingBase64, EncodingBase64URL} {
+		if key, err := encoding.DecodeString(encoded); err == nil && validateKeySize(len(key)) == nil {
+			return key, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%w: key is not a hex, base64, or PEM encoded key of a valid size", ErrInvalidEncoding)
+}
+
+// WriteEncryptedKeyFile encrypts the provided key with a passphrase and writes it to the provided path, readable only
+// by its owner. An existing file is not overwritten.
+func WriteEncryptedKeyFile(path string, key []byte, passphrase string, config hash.Config) error {
+	data, err := MarshalEncryptedKey(key, passphrase, config)
+	if err != nil {
+		return err
+	}
+
+	return writeKeyFile(path, data)
+}
+
+// WriteKeyFile writes the provided key PEM encoded to the provided path, readable only by its owner. An existing file
+// is not overwritten.
+func WriteKeyFile(path string, key []byte) error {
+	data, err := MarshalKey(key)
+	if err != nil {
+		return err
+	}
+
+	return writeKeyFile(path, data)
+}
+
+// parsePEMKey decodes a PEM encoded plain key
+func parsePEMKey(data []byte) ([]byte, error) {
+	pemBlock, _ := pem.Decode(data)
+	if pemBlock == nil {
+		return nil, fmt.Errorf("%w: invalid PEM data", ErrInvalidEncoding)
+	}
+
+	switch pemBlock.Type {
+	case pemTypeKey:
+	case pemTypeEncryptedKey:
+		return nil, ErrKeyEncrypted
+	default:
+		return nil, fmt.Errorf("%w: unexpected PEM block type: %s", ErrInvalidEncoding, pemBlock.Type)
+	}
+
+	if err := validateKeySize(len(pemBlock.Bytes)); err != nil {
+		return nil, err
+	}
+
+	return pemBlock.Bytes, nil
+}
+
+// readKeyFile reads a key file, failing if it is accessible by anyone but its owner
+func readKeyFile(path string) ([]byte, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	// windows does not report unix permission bits
+	if runtime.GOOS != "windows" && info.Mode().Perm()&0o077 != 0 {
+		return nil, fmt.Errorf("%w: %s has mode %#o", ErrInsecurePermissions, path, info.Mode().Perm())
+	}
+
+	/* #nosec */
+	return os.ReadFile(path)
+}
+
+// validateKeySize checks that size is one of keySizes
+func validateKeySize(size int) error {
+	if !slices.Contains(keySizes, size) {
+		return fmt.Errorf("%w: %d bytes", ErrInvalidKeySize, size)
+	}
+
+	return nil
+}
+
+// writeKeyFile writes data to a new file readable only by its owner
+func writeKeyFile(path string, data []byte) error {
+	/* #nosec */
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, keyFileMode)
+	if err != nil {
+		return err
+	}
+
+	if _, err = f.Write(data); err != nil {
+		_ = f.Close()
+		return err
+	}
+
+	return f.Close()
+}
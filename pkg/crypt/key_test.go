@@ -0,0 +1,309 @@
+/* SPDX-License-Identifier: BSD-2-Clause
+
+Copyright (c) 2020-2026, Jef Oliver
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without modification,
+are permitted provided that the following conditions are met:
+
+  1. Redistributions of source code must retain the above copyright notice, this
+     list of conditions and the following disclaimer.
+
+  2. Redistributions in binary form must reproduce the above copyright notice
+     this list of conditions and the following disclaimer in the documentation
+     and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package crypt
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"math"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestGenerateKey(t *testing.T) {
+	tests := []struct {
+		name    string
+		size    int
+		wantErr bool
+	}{
+		{"zero", 0, true},
+		{"bad size", 20, true},
+		{"aes-128", 16, false},
+		{"aes-192", 24, false},
+		{"aes-256", 32, false},
+		{"siv-256", 64, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := GenerateKey(tt.size)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("GenerateKey() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidKeySize) {
+					t.Errorf("GenerateKey() error = %v, want %v", err, ErrInvalidKeySize)
+				}
+				return
+			}
+			if len(got) != tt.size {
+				t.Errorf("GenerateKey() len = %d, want %d", len(got), tt.size)
+			}
+			if bytes.Equal(got, make([]byte, tt.size)) {
+				t.Error("GenerateKey() returned an empty key")
+			}
+		})
+	}
+}
+
+func TestLoadKeyFromEnv(t *testing.T) {
+	key := []byte("testKeyThirtyTwoBytesLong32Bytes")
+
+	t.Run("set", func(t *testing.T) {
+		t.Setenv("GO_AUTH_TEST_KEY", base64.StdEncoding.EncodeToString(key))
+
+		got, err := LoadKeyFromEnv("GO_AUTH_TEST_KEY")
+		if err != nil {
+			t.Fatalf("LoadKeyFromEnv() error = %v", err)
+		}
+		if !bytes.Equal(got, key) {
+			t.Errorf("LoadKeyFromEnv() = %v, want %v", got, key)
+		}
+	})
+
+	t.Run("not set", func(t *testing.T) {
+		if _, err := LoadKeyFromEnv("GO_AUTH_TEST_KEY_NOT_SET"); err == nil {
+			t.Error("LoadKeyFromEnv() error = nil, want error")
+		}
+	})
+}
+
+// nolint: gocognit
+func TestLoadKeyFromFile(t *testing.T) {
+	key := []byte("testKeyThirtyTwoBytesLong32Bytes")
+
+	tests := []struct {
+		name    string
+		data    string
+		mode    os.FileMode
+		wantErr error
+	}{
+		{"hex", hex.EncodeToString(key) + "\n", 0o600, nil},
+		{"insecure", hex.EncodeToString(key), 0o644, ErrInsecurePermissions},
+		{"bad size", "9d9a7ae2334a", 0o600, ErrInvalidEncoding},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.mode != 0o600 && runtime.GOOS == "windows" {
+				t.Skip("file permissions are not checked on windows")
+			}
+
+			path := filepath.Join(t.TempDir(), "key")
+			if err := os.WriteFile(path, []byte(tt.data), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.Chmod(path, tt.mode); err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := LoadKeyFromFile(path)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("LoadKeyFromFile() error = %v, want %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr == nil && !bytes.Equal(got, key) {
+				t.Errorf("LoadKeyFromFile() = %v, want %v", got, key)
+			}
+		})
+	}
+
+	t.Run("missing file", func(t *testing.T) {
+		if _, err := LoadKeyFromFile(filepath.Join(t.TempDir(), "missing")); err == nil {
+			t.Error("LoadKeyFromFile() error = nil, want error")
+		}
+	})
+}
+
+// nolint: gocognit
+func TestParseKey(t *testing.T) {
+	key := []byte("testKeyThirtyTwoBytesLong32Bytes")
+	pemKey, err := MarshalKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	encryptedKey, err := MarshalEncryptedKey(key, "correct horse", testPassphraseConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		data    []byte
+		wantErr error
+	}{
+		{"hex", []byte(hex.EncodeToString(key)), nil},
+		{"base64", []byte(base64.StdEncoding.EncodeToString(key)), nil},
+		{"base64url", []byte(base64.RawURLEncoding.EncodeToString(key)), nil},
+		{"pem", pemKey, nil},
+		{"surrounding whitespace", []byte("  " + hex.EncodeToString(key) + "\n"), nil},
+		{"empty", []byte(" \n"), ErrNoData},
+		{"bad encoding", []byte("not a key"), ErrInvalidEncoding},
+		{"bad size", []byte(hex.EncodeToString(key[:20])), ErrInvalidEncoding},
+		{"encrypted pem", encryptedKey, ErrKeyEncrypted},
+		{"bad pem", []byte("-----BEGIN EJ CRYPT KEY-----\n"), ErrInvalidEncoding},
+		{"wrong pem type", []byte("-----BEGIN CERTIFICATE-----\nAAAA\n-----END CERTIFICATE-----\n"), ErrInvalidEncoding},
+		{"bad pem size", []byte("-----BEGIN EJ CRYPT KEY-----\nAAAA\n-----END EJ CRYPT KEY-----\n"), ErrInvalidKeySize},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseKey(tt.data)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("ParseKey() error = %v, want %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr == nil && !bytes.Equal(got, key) {
+				t.Errorf("ParseKey() = %v, want %v", got, key)
+			}
+		})
+	}
+}
+
+func TestParseEncryptedKey(t *testing.T) {
+	key := []byte("testKeyThirtyTwoBytesLong32Bytes")
+	pemKey, err := MarshalKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	encryptedKey, err := MarshalEncryptedKey(key, "correct horse", testPassphraseConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		data       []byte
+		passphrase string
+		wantErr    error
+	}{
+		{"good", encryptedKey, "correct horse", nil},
+		{"wrong passphrase", encryptedKey, "battery staple", ErrAuthenticationFailed},
+		{"not encrypted", pemKey, "correct horse", ErrInvalidEncoding},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseEncryptedKey(tt.data, tt.passphrase)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("ParseEncryptedKey() error = %v, want %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr == nil && !bytes.Equal(got, key) {
+				t.Errorf("ParseEncryptedKey() = %v, want %v", got, key)
+			}
+		})
+	}
+
+	t.Run("no passphrase", func(t *testing.T) {
+		if _, err := ParseEncryptedKey(encryptedKey, ""); err == nil {
+			t.Error("ParseEncryptedKey() error = nil, want error")
+		}
+	})
+
+	t.Run("parameters too large to parse", func(t *testing.T) {
+		config := testPassphraseConfig()
+		config.Memory = EncryptedKeyMaxMemory + 1
+		if _, err := MarshalEncryptedKey(key, "correct horse", config); !errors.Is(err, ErrPassphraseCost) {
+			t.Errorf("MarshalEncryptedKey() error = %v, want %v", err, ErrPassphraseCost)
+		}
+	})
+
+	t.Run("header parameters too large", func(t *testing.T) {
+		pemBlock, _ := pem.Decode(encryptedKey)
+		binary.BigEndian.PutUint32(pemBlock.Bytes[6:10], math.MaxUint32)
+
+		if _, err := ParseEncryptedKey(pem.EncodeToMemory(pemBlock), "correct horse"); !errors.Is(err,
+			ErrPassphraseCost) {
+			t.Errorf("ParseEncryptedKey() error = %v, want %v", err, ErrPassphraseCost)
+		}
+	})
+}
+
+// nolint: gocognit
+func TestWriteKeyFile(t *testing.T) {
+	key, err := GenerateKey(32)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("plain", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "key")
+		if err = WriteKeyFile(path, key); err != nil {
+			t.Fatalf("WriteKeyFile() error = %v", err)
+		}
+
+		got, err := LoadKeyFromFile(path)
+		if err != nil {
+			t.Fatalf("LoadKeyFromFile() error = %v", err)
+		}
+		if !bytes.Equal(got, key) {
+			t.Errorf("LoadKeyFromFile() = %v, want %v", got, key)
+		}
+
+		if err = WriteKeyFile(path, key); err == nil {
+			t.Error("WriteKeyFile() overwrote an existing file")
+		}
+	})
+
+	t.Run("encrypted", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "key")
+		if err = WriteEncryptedKeyFile(path, key, "correct horse", testPassphraseConfig()); err != nil {
+			t.Fatalf("WriteEncryptedKeyFile() error = %v", err)
+		}
+
+		got, err := LoadEncryptedKeyFromFile(path, "correct horse")
+		if err != nil {
+			t.Fatalf("LoadEncryptedKeyFromFile() error = %v", err)
+		}
+		if !bytes.Equal(got, key) {
+			t.Errorf("LoadEncryptedKeyFromFile() = %v, want %v", got, key)
+		}
+
+		if _, err = LoadKeyFromFile(path); !errors.Is(err, ErrKeyEncrypted) {
+			t.Errorf("LoadKeyFromFile() error = %v, want %v", err, ErrKeyEncrypted)
+		}
+	})
+
+	t.Run("bad size", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "key")
+		if err = WriteKeyFile(path, key[:20]); !errors.Is(err, ErrInvalidKeySize) {
+			t.Errorf("WriteKeyFile() error = %v, want %v", err, ErrInvalidKeySize)
+		}
+		if _, err = os.Stat(path); !os.IsNotExist(err) {
+			t.Errorf("WriteKeyFile() created a file for an invalid key")
+		}
+	})
+}
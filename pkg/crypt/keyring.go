@@ -0,0 +1,230 @@
+/* SPDX-License-Identifier: BSD-2-Clause
+
+Copyright (c) 2020-2026, Jef Oliver
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without modification,
+are permitted provided that the following conditions are met:
+
+  1. Redistributions of source code must retain the above copyright notice, this
+     list of conditions and the following disclaimer.
+
+  2. Redistributions in binary form must reproduce the above copyright notice
+     this list of conditions and the following disclaimer in the documentation
+     and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package crypt
+
+import (
+	"fmt"
+	"slices"
+	"sync"
+)
+
+type keyring struct {
+	keys    map[uint32]*block
+	lock    sync.RWMutex
+	primary uint32
+}
+
+// AddKey adds a key, identified by the provided ID, to the keyring
+func (k *keyring) AddKey(id uint32, key []byte) error {
+	newBlock, err := newAESBlock(key, id)
+	if err != nil {
+		return err
+	}
+
+	k.lock.Lock()
+	defer k.lock.Unlock()
+
+	if _, ok := k.keys[id]; ok {
+		return fmt.Errorf("key already exists in keyring: %d", id)
+	}
+
+	k.keys[id] = newBlock
+
+	return nil
+}
+
+// Decrypt decrypts the provided data
+func (k *keyring) Decrypt(data []byte) ([]byte, error) {
+	return k.DecryptWithAAD(data, nil)
+}
+
+// DecryptFromString decrypts data stored in a hex encoded string
+func (k *keyring) DecryptFromString(data string) ([]byte, error) {
+	return k.DecryptFromStringWithAAD(data, nil)
+}
+
+// DecryptFromStringWithAAD decrypts data stored in a hex encoded string, authenticating it against the provided
+// additional data
+func (k *keyring) DecryptFromStringWithAAD(data string, aad []byte) ([]byte, error) {
+	dataToDecode, err := decodeString(data, EncodingHex)
+	if err != nil {
+		return nil, err
+	}
+
+	return k.DecryptWithAAD(dataToDecode, aad)
+}
+
+// DecryptWithAAD decrypts the provided data with the key identified in its envelope, authenticating it against the
+// provided additional data. Legacy, unframed, data does not identify its key, so it is decrypted with each key the
+// keyring holds, starting with the primary key.
+func (k *keyring) DecryptWithAAD(data []byte, aad []byte) ([]byte, error) {
+	if len(data) == 0 {
+		return nil, ErrNoData
+	}
+
+	env, err := ParseEnvelope(data)
+
+	k.lock.RLock()
+	keyBlock, ok := k.keys[env.KeyID]
+	legacyBlocks := k.legacyBlocks()
+	k.lock.RUnlock()
+
+	var unknownKeyErr error
+	if err == nil && !env.Legacy {
+		if !ok {
+			unknownKeyErr = fmt.Errorf("%w: unknown or retired key: %d", ErrAuthenticationFailed, env.KeyID)
+		} else if env.Algorithm == keyBlock.algorithm {
+			if ret, openErr := openEnvelope(keyBlock.aead, &env, aad); openErr == nil {
+				return ret, nil
+			}
+		}
+	}
+
+	// Data that cannot be opened as a framed envelope may still be legacy data whose nonce happens to start with the
+	// envelope magic, so a legacy open is always attempted before failing.
+	legacyEnv, err := parseLegacyEnvelope(data)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, legacyBlock := range legacyBlocks {
+		var ret []byte
+		if ret, err = openEnvelope(legacyBlock.aead, &legacyEnv, aad); err == nil {
+			return ret, nil
+		}
+	}
+
+	if unknownKeyErr != nil {
+		return nil, unknownKeyErr
+	}
+
+	return nil, err
+}
+
+// Encrypt encrypts the provided data with the primary key
+func (k *keyring) Encrypt(data []byte) ([]byte, error) {
+	return k.EncryptWithAAD(data, nil)
+}
+
+// EncryptToString encrypts the provided data with the primary key and returns it as a hex encoded string
+func (k *keyring) EncryptToString(data []byte) (string, error) {
+	return k.EncryptToStringWithAAD(data, nil)
+}
+
+// EncryptToStringWithAAD encrypts the provided data with the primary key, binding it to the provided additional
+// data, and returns it as a hex encoded string
+func (k *keyring) EncryptToStringWithAAD(data []byte, aad []byte) (string, error) {
+	var ret string
+
+	encryptedData, err := k.EncryptWithAAD(data, aad)
+	if err == nil {
+		ret = EncodingHex.EncodeToString(encryptedData)
+	}
+
+	return ret, err
+}
+
+// EncryptWithAAD encrypts the provided data with the primary key, binding it to the provided additional data
+func (k *keyring) EncryptWithAAD(data []byte, aad []byte) ([]byte, error) {
+	k.lock.RLock()
+	keyBlock := k.keys[k.primary]
+	k.lock.RUnlock()
+
+	return keyBlock.EncryptWithAAD(data, aad)
+}
+
+// Keys returns the IDs of all keys held by the keyring, sorted in ascending order
+func (k *keyring) Keys() []uint32 {
+	k.lock.RLock()
+	defer k.lock.RUnlock()
+
+	ret := make([]uint32, 0, len(k.keys))
+	for id := range k.keys {
+		ret = append(ret, id)
+	}
+
+	slices.Sort(ret)
+
+	return ret
+}
+
+// legacyBlocks returns the blocks for every key held by the keyring, with the primary key first. The caller must hold
+// the keyring lock.
+func (k *keyring) legacyBlocks() []*block {
+	ret := make([]*block, 0, len(k.keys))
+	ret = append(ret, k.keys[k.primary])
+
+	for id, keyBlock := range k.keys {
+		if id != k.primary {
+			ret = append(ret, keyBlock)
+		}
+	}
+
+	return ret
+}
+
+// Primary returns the ID of the key used for encryption
+func (k *keyring) Primary() uint32 {
+	k.lock.RLock()
+	defer k.lock.RUnlock()
+
+	return k.primary
+}
+
+// Promote makes the key identified by the provided ID the primary key
+func (k *keyring) Promote(id uint32) error {
+	k.lock.Lock()
+	defer k.lock.Unlock()
+
+	if _, ok := k.keys[id]; !ok {
+		return fmt.Errorf("key not found in keyring: %d", id)
+	}
+
+	k.primary = id
+
+	return nil
+}
+
+// Retire removes the key identified by the provided ID from the keyring. Data encrypted with a retired key can no
+// longer be decrypted. The primary key cannot be retired.
+func (k *keyring) Retire(id uint32) error {
+	k.lock.Lock()
+	defer k.lock.Unlock()
+
+	if _, ok := k.keys[id]; !ok {
+		return fmt.Errorf("key not found in keyring: %d", id)
+	}
+
+	if id == k.primary {
+		return fmt.Errorf("cannot retire primary key: %d", id)
+	}
+
+	delete(k.keys, id)
+
+	return nil
+}
@@ -0,0 +1,320 @@
+/* SPDX-License-Identifier: BSD-2-Clause
+
+Copyright (c) 2020-2026, Jef Oliver
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without modification,
+are permitted provided that the following conditions are met:
+
+  1. Redistributions of source code must retain the above copyright notice, this
+     list of conditions and the following disclaimer.
+
+  2. Redistributions in binary form must reproduce the above copyright notice
+     this list of conditions and the following disclaimer in the documentation
+     and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package crypt
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func newTestKeyring(t *testing.T) Keyring {
+	t.Helper()
+
+	ring, err := NewKeyring(1, []byte("testKeySixteen16"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = ring.AddKey(2, []byte("testKeyTwentyFourBytes24")); err != nil {
+		t.Fatal(err)
+	}
+
+	return ring
+}
+
+func Test_keyringAddKey(t *testing.T) {
+	tests := []struct {
+		name    string
+		id      uint32
+		key     string
+		wantErr bool
+	}{
+		{"bad key", 3, "9d9a7ae2334a", true},
+		{"existing id", 1, "testKeySixteen16", true},
+		{"good", 3, "testKeyThirtyTwoBytesLong32Bytes", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tester := newTestKeyring(t)
+
+			if err := tester.AddKey(tt.id, []byte(tt.key)); (err != nil) != tt.wantErr {
+				t.Errorf("keyring.AddKey() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_keyringDecryptWithAAD(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    []byte
+		wantErr bool
+	}{
+		{"no data", nil, true},
+		{"too short", []byte{0, 0, 1}, true},
+		{"bad envelope", []byte{'E', 'J', 'C', 1, 1, 0, 0}, true},
+		{"unknown key", []byte{'E', 'J', 'C', 1, 1, 0, 0, 0, 9, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16},
+			true},
+		{"wrong algorithm", append([]byte{'E', 'J', 'C', 1, 2, 0, 0, 0, 1}, make([]byte, 56)...), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tester := newTestKeyring(t)
+
+			got, err := tester.DecryptWithAAD(tt.data, nil)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("keyring.DecryptWithAAD() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != nil {
+				t.Errorf("keyring.DecryptWithAAD() = %v, want nil", got)
+			}
+		})
+	}
+}
+
+func Test_keyringKeys(t *testing.T) {
+	t.Run("good", func(t *testing.T) {
+		tester := newTestKeyring(t)
+		if err := tester.AddKey(0, []byte("testKeyThirtyTwoBytesLong32Bytes")); err != nil {
+			t.Fatal(err)
+		}
+
+		want := []uint32{0, 1, 2}
+		if got := tester.Keys(); !reflect.DeepEqual(got, want) {
+			t.Errorf("keyring.Keys() = %v, want %v", got, want)
+		}
+	})
+}
+
+func Test_keyringPromote(t *testing.T) {
+	tests := []struct {
+		name        string
+		id          uint32
+		wantErr     bool
+		wantPrimary uint32
+	}{
+		{"unknown key", 9, true, 1},
+		{"good", 2, false, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tester := newTestKeyring(t)
+
+			if err := tester.Promote(tt.id); (err != nil) != tt.wantErr {
+				t.Errorf("keyring.Promote() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got := tester.Primary(); got != tt.wantPrimary {
+				t.Errorf("keyring.Primary() = %d, want %d", got, tt.wantPrimary)
+			}
+		})
+	}
+}
+
+func Test_keyringRetire(t *testing.T) {
+	tests := []struct {
+		name     string
+		id       uint32
+		wantErr  bool
+		wantKeys []uint32
+	}{
+		{"unknown key", 9, true, []uint32{1, 2}},
+		{"primary key", 1, true, []uint32{1, 2}},
+		{"good", 2, false, []uint32{1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tester := newTestKeyring(t)
+
+			if err := tester.Retire(tt.id); (err != nil) != tt.wantErr {
+				t.Errorf("keyring.Retire() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got := tester.Keys(); !reflect.DeepEqual(got, tt.wantKeys) {
+				t.Errorf("keyring.Keys() = %v, want %v", got, tt.wantKeys)
+			}
+		})
+	}
+}
+
+// nolint: gocognit
+func Test_keyringRotation(t *testing.T) {
+	t.Run("keyring rotation", func(t *testing.T) {
+		data := []byte("this is test data")
+		aad := []byte("cookie:oauth_state")
+
+		tester := newTestKeyring(t)
+
+		oldData, err := tester.EncryptToStringWithAAD(data, aad)
+		if err != nil {
+			t.Fatalf("keyring.EncryptToStringWithAAD() error = %v", err)
+		}
+
+		if err = tester.Promote(2); err != nil {
+			t.Fatalf("keyring.Promote() error = %v", err)
+		}
+
+		newData, err := tester.Encrypt(data)
+		if err != nil {
+			t.Fatalf("keyring.Encrypt() error = %v", err)
+		}
+		env, err := ParseEnvelope(newData)
+		if err != nil {
+			t.Fatalf("ParseEnvelope() error = %v", err)
+		}
+		if env.KeyID != 2 {
+			t.Errorf("keyring.Encrypt() key id = %d, want 2", env.KeyID)
+		}
+
+		got, err := tester.DecryptFromStringWithAAD(oldData, aad)
+		if err != nil {
+			t.Fatalf("keyring.DecryptFromStringWithAAD() old key error = %v", err)
+		}
+		if !bytes.Equal(got, data) {
+			t.Errorf("keyring.DecryptFromStringWithAAD() old key = %v, want %v", got, data)
+		}
+
+		got, err = tester.Decrypt(newData)
+		if err != nil {
+			t.Fatalf("keyring.Decrypt() new key error = %v", err)
+		}
+		if !bytes.Equal(got, data) {
+			t.Errorf("keyring.Decrypt() new key = %v, want %v", got, data)
+		}
+
+		if err = tester.Retire(1); err != nil {
+			t.Fatalf("keyring.Retire() error = %v", err)
+		}
+
+		if _, err = tester.DecryptFromStringWithAAD(oldData, aad); err == nil {
+			t.Error("keyring.DecryptFromStringWithAAD() retired key error = nil, want error")
+		}
+	})
+}
+
+func Test_keyringDecryptLegacy(t *testing.T) {
+	legacyData := "62cee51629a72beee654a8fbc6d81ed07e981c15889c80baf636b73f22f95cc0e659dbeebe278792c256dd096f"
+
+	t.Run("legacy data after promotion", func(t *testing.T) {
+		tester := newTestKeyring(t)
+
+		if err := tester.Promote(2); err != nil {
+			t.Fatalf("keyring.Promote() error = %v", err)
+		}
+
+		got, err := tester.DecryptFromString(legacyData)
+		if err != nil {
+			t.Fatalf("keyring.DecryptFromString() error = %v", err)
+		}
+		if string(got) != "this is test data" {
+			t.Errorf("keyring.DecryptFromString() = %s, want \"this is test data\"", got)
+		}
+
+		if err = tester.Retire(1); err != nil {
+			t.Fatalf("keyring.Retire() error = %v", err)
+		}
+
+		if _, err = tester.DecryptFromString(legacyData); !errors.Is(err, ErrAuthenticationFailed) {
+			t.Errorf("keyring.DecryptFromString() retired key error = %v, want %v", err, ErrAuthenticationFailed)
+		}
+	})
+
+	t.Run("legacy data that looks framed", func(t *testing.T) {
+		tester := newTestKeyring(t)
+
+		aesCipher, err := aes.NewCipher([]byte("testKeyTwentyFourBytes24"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		aead, err := cipher.NewGCM(aesCipher)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// a legacy nonce that parses as a framed envelope for the unknown key 9
+		nonce := []byte{'E', 'J', 'C', 1, 1, 0, 0, 0, 9, 0, 0, 0}
+		data := aead.Seal(nonce, nonce, []byte("this is test data"), nil)
+
+		got, err := tester.Decrypt(data)
+		if err != nil {
+			t.Fatalf("keyring.Decrypt() error = %v", err)
+		}
+		if string(got) != "this is test data" {
+			t.Errorf("keyring.Decrypt() = %s, want \"this is test data\"", got)
+		}
+	})
+
+	t.Run("legacy data uses primary key", func(t *testing.T) {
+		tester := newTestKeyring(t)
+
+		got, err := tester.DecryptFromString(
+			"62cee51629a72beee654a8fbc6d81ed07e981c15889c80baf636b73f22f95cc0e659dbeebe278792c256dd096f")
+		if err != nil {
+			t.Fatalf("keyring.DecryptFromString() error = %v", err)
+		}
+		if string(got) != "this is test data" {
+			t.Errorf("keyring.DecryptFromString() = %s, want \"this is test data\"", got)
+		}
+	})
+}
+
+func FuzzKeyringDecrypt(f *testing.F) {
+	ring, err := NewKeyring(1, []byte("testKeySixteen16"))
+	if err != nil {
+		f.Fatal(err)
+	}
+	if err = ring.AddKey(2, []byte("testKeyTwentyFourBytes24")); err != nil {
+		f.Fatal(err)
+	}
+
+	encryptedData, err := ring.Encrypt([]byte("this is test data"))
+	if err != nil {
+		f.Fatal(err)
+	}
+
+	f.Add(encryptedData)
+	f.Add([]byte{})
+	f.Add([]byte{'E', 'J', 'C', 1, 1, 0, 0, 0, 9})
+	f.Add(append([]byte{'E', 'J', 'C', 1, 2, 0, 0, 0, 1}, make([]byte, 56)...))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		got, err := ring.Decrypt(data)
+		if err == nil && !bytes.Equal(got, []byte("this is test data")) {
+			t.Errorf("keyring.Decrypt() = %v, want error", got)
+		}
+	})
+}
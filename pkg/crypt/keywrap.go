@@ -0,0 +1,217 @@
+/* SPDX-License-Identifier: BSD-2-Clause
+
+Copyright (c) 2020-2026, Jef Oliver
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without modification,
+are permitted provided that the following conditions are met:
+
+  1. Redistributions of source code must retain the above copyright notice, this
+     list of conditions and the following disclaimer.
+
+  2. Redistributions in binary form must reproduce the above copyright notice
+     this list of conditions and the following disclaimer in the documentation
+     and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package crypt
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/subtle"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+)
+
+// keyWrapBlockSize is the size, in bytes, of the semiblocks key wrap operates on
+const keyWrapBlockSize = 8
+
+var (
+	// keyWrapIV is the default initial value from RFC 3394
+	keyWrapIV = []byte{0xa6, 0xa6, 0xa6, 0xa6, 0xa6, 0xa6, 0xa6, 0xa6}
+	// keyWrapPaddingIV is the constant half of the alternative initial value from RFC 5649
+	keyWrapPaddingIV = []byte{0xa6, 0x59, 0x59, 0xa6}
+)
+
+// UnwrapKey unwraps a key wrapped with the AES key wrap algorithm from RFC 3394, using the provided AES key
+// encryption key.
+func UnwrapKey(kek, wrappedKey []byte) ([]byte, error) {
+	b, err := aes.NewCipher(kek)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(wrappedKey) < 3*keyWrapBlockSize || len(wrappedKey)%keyWrapBlockSize != 0 {
+		return nil, fmt.Errorf("%w: invalid wrapped key length: %d", ErrCiphertextTooShort, len(wrappedKey))
+	}
+
+	iv, key := keyUnwrap(b, wrappedKey)
+	if subtle.ConstantTimeCompare(iv, keyWrapIV) != 1 {
+		clear(key)
+		return nil, ErrAuthenticationFailed
+	}
+
+	return key, nil
+}
+
+// UnwrapKeyWithPadding unwraps a key wrapped with the AES key wrap with padding algorithm from RFC 5649, using the
+// provided AES key encryption key.
+func UnwrapKeyWithPadding(kek, wrappedKey []byte) ([]byte, error) {
+	b, err := aes.NewCipher(kek)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(wrappedKey) < 2*keyWrapBlockSize || len(wrappedKey)%keyWrapBlockSize != 0 {
+		return nil, fmt.Errorf("%w: invalid wrapped key length: %d", ErrCiphertextTooShort, len(wrappedKey))
+	}
+
+	var iv, key []byte
+
+	if len(wrappedKey) == 2*keyWrapBlockSize {
+		// a single semiblock of key data is encrypted with the initial value as one AES block
+		plain := make([]byte, aes.BlockSize)
+		b.Decrypt(plain, wrappedKey)
+		iv, key = plain[:keyWrapBlockSize], plain[keyWrapBlockSize:]
+	} else {
+		iv, key = keyUnwrap(b, wrappedKey)
+	}
+
+	size := int(binary.BigEndian.Uint32(iv[4:]))
+	valid := subtle.ConstantTimeCompare(iv[:4], keyWrapPaddingIV) == 1 &&
+		size > len(key)-keyWrapBlockSize && size <= len(key)
+
+	if valid {
+		for _, c := range key[size:] {
+			valid = valid && c == 0
+		}
+	}
+
+	if !valid {
+		clear(key)
+		return nil, ErrAuthenticationFailed
+	}
+
+	return key[:size], nil
+}
+
+// WrapKey wraps a key with the AES key wrap algorithm from RFC 3394, using the provided AES key encryption key. The
+// key must be a multiple of 8 bytes and at least 16 bytes long.
+func WrapKey(kek, key []byte) ([]byte, error) {
+	b, err := aes.NewCipher(kek)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(key) < 2*keyWrapBlockSize || len(key)%keyWrapBlockSize != 0 {
+		return nil, fmt.Errorf("%w: key to wrap must be a multiple of 8 bytes and at least 16 bytes: %d bytes",
+			ErrInvalidKeySize, len(key))
+	}
+
+	return keyWrap(b, keyWrapIV, key), nil
+}
+
+// WrapKeyWithPadding wraps a key of any length with the AES key wrap with padding algorithm from RFC 5649, using the
+// provided AES key encryption key.
+func WrapKeyWithPadding(kek, key []byte) ([]byte, error) {
+	b, err := aes.NewCipher(kek)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(key) == 0 {
+		return nil, ErrNoData
+	}
+
+	if uint64(len(key)) > math.MaxUint32 {
+		return nil, errors.New("key to wrap is too long")
+	}
+
+	iv := make([]byte, keyWrapBlockSize)
+	copy(iv, keyWrapPaddingIV)
+	/* #nosec */
+	binary.BigEndian.PutUint32(iv[4:], uint32(len(key)))
+
+	padded := make([]byte, (len(key)+keyWrapBlockSize-1)/keyWrapBlockSize*keyWrapBlockSize)
+	copy(padded, key)
+	defer clear(padded)
+
+	if len(padded) == keyWrapBlockSize {
+		ret := make([]byte, aes.BlockSize)
+		b.Encrypt(ret, append(iv, padded...))
+
+		return ret, nil
+	}
+
+	return keyWrap(b, iv, padded), nil
+}
+
+// keyUnwrap performs the RFC 3394 unwrapping process, returning the recovered initial value and data. The initial
+// value must be checked by the caller.
+func keyUnwrap(b cipher.Block, data []byte) ([]byte, []byte) {
+	n := len(data)/keyWrapBlockSize - 1
+	a := binary.BigEndian.Uint64(data[:keyWrapBlockSize])
+	ret := make([]byte, len(data)-keyWrapBlockSize)
+	copy(ret, data[keyWrapBlockSize:])
+
+	buf := make([]byte, aes.BlockSize)
+	for j := 5; j >= 0; j-- {
+		for i := n; i >= 1; i-- {
+			r := ret[(i-1)*keyWrapBlockSize : i*keyWrapBlockSize]
+
+			/* #nosec */
+			binary.BigEndian.PutUint64(buf[:keyWrapBlockSize], a^uint64(n*j+i))
+			copy(buf[keyWrapBlockSize:], r)
+			b.Decrypt(buf, buf)
+
+			a = binary.BigEndian.Uint64(buf[:keyWrapBlockSize])
+			copy(r, buf[keyWrapBlockSize:])
+		}
+	}
+
+	iv := make([]byte, keyWrapBlockSize)
+	binary.BigEndian.PutUint64(iv, a)
+
+	return iv, ret
+}
+
+// keyWrap performs the RFC 3394 wrapping process on data that is a multiple of 8 bytes, using the provided initial
+// value
+func keyWrap(b cipher.Block, iv, data []byte) []byte {
+	n := len(data) / keyWrapBlockSize
+	ret := make([]byte, keyWrapBlockSize+len(data))
+	copy(ret, iv)
+	copy(ret[keyWrapBlockSize:], data)
+
+	buf := make([]byte, aes.BlockSize)
+	for j := range 6 {
+		for i := 1; i <= n; i++ {
+			r := ret[i*keyWrapBlockSize : (i+1)*keyWrapBlockSize]
+
+			copy(buf, ret[:keyWrapBlockSize])
+			copy(buf[keyWrapBlockSize:], r)
+			b.Encrypt(buf, buf)
+
+			/* #nosec */
+			binary.BigEndian.PutUint64(ret[:keyWrapBlockSize],
+				binary.BigEndian.Uint64(buf[:keyWrapBlockSize])^uint64(n*j+i))
+			copy(r, buf[keyWrapBlockSize:])
+		}
+	}
+
+	return ret
+}
@@ -0,0 +1,247 @@
+/* SPDX-License-Identifier: BSD-2-Clause
+
+Copyright (c) 2020-2026, Jef Oliver
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without modification,
+are permitted provided that the following conditions are met:
+
+  1. Redistributions of source code must retain the above copyright notice, this
+     list of conditions and the following disclaimer.
+
+  2. Redistributions in binary form must reproduce the above copyright notice
+     this list of conditions and the following disclaimer in the documentation
+     and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package crypt
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"testing"
+)
+
+// nolint: gocognit
+func TestWrapKey(t *testing.T) {
+	// test vectors from https://www.rfc-editor.org/rfc/rfc3394#section-4
+	tests := []struct {
+		name string
+		kek  string
+		key  string
+		want string
+	}{
+		{"128 bit key with 128 bit kek", "000102030405060708090a0b0c0d0e0f", "00112233445566778899aabbccddeeff",
+			"1fa68b0a8112b447aef34bd8fb5a7b829d3e862371d2cfe5"},
+		{"128 bit key with 192 bit kek", "000102030405060708090a0b0c0d0e0f1011121314151617",
+			"00112233445566778899aabbccddeeff", "96778b25ae6ca435f92b5b97c050aed2468ab8a17ad84e5d"},
+		{"128 bit key with 256 bit kek", "000102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f",
+			"00112233445566778899aabbccddeeff", "64e8c3f9ce0f5ba263e9777905818a2a93c8191e7d6e8ae7"},
+		{"192 bit key with 192 bit kek", "000102030405060708090a0b0c0d0e0f1011121314151617",
+			"00112233445566778899aabbccddeeff0001020304050607",
+			"031d33264e15d33268f24ec260743edce1c6c7ddee725a936ba814915c6762d2"},
+		{"192 bit key with 256 bit kek", "000102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f",
+			"00112233445566778899aabbccddeeff0001020304050607",
+			"a8f9bc1612c68b3ff6e6f4fbe30e71e4769c8b80a32cb8958cd5d17d6b254da1"},
+		{"256 bit key with 256 bit kek", "000102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f",
+			"00112233445566778899aabbccddeeff000102030405060708090a0b0c0d0e0f",
+			"28c9f404c4b810f4cbccb35cfb87f8263f5786e2d80ed326cbc7f0e71a99f43bfb988b9b7a02dd21"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kek := mustDecodeHex(t, tt.kek)
+			key := mustDecodeHex(t, tt.key)
+
+			got, err := WrapKey(kek, key)
+			if err != nil {
+				t.Fatalf("WrapKey() error = %v", err)
+			}
+			if hex.EncodeToString(got) != tt.want {
+				t.Errorf("WrapKey() = %x, want %s", got, tt.want)
+			}
+
+			unwrapped, err := UnwrapKey(kek, got)
+			if err != nil {
+				t.Fatalf("UnwrapKey() error = %v", err)
+			}
+			if !bytes.Equal(unwrapped, key) {
+				t.Errorf("UnwrapKey() = %x, want %x", unwrapped, key)
+			}
+		})
+	}
+}
+
+func TestWrapKeyErrors(t *testing.T) {
+	kek := mustDecodeHex(t, "000102030405060708090a0b0c0d0e0f")
+
+	tests := []struct {
+		name    string
+		kek     []byte
+		key     []byte
+		wantErr error
+	}{
+		{"bad kek", kek[:5], make([]byte, 16), nil},
+		{"too short", kek, make([]byte, 8), ErrInvalidKeySize},
+		{"not a multiple of 8", kek, make([]byte, 20), ErrInvalidKeySize},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := WrapKey(tt.kek, tt.key)
+			if err == nil {
+				t.Error("WrapKey() error = nil, want error")
+				return
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("WrapKey() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestUnwrapKeyErrors(t *testing.T) {
+	kek := mustDecodeHex(t, "000102030405060708090a0b0c0d0e0f")
+	wrapped := mustDecodeHex(t, "1fa68b0a8112b447aef34bd8fb5a7b829d3e862371d2cfe5")
+
+	modified := bytes.Clone(wrapped)
+	modified[10] ^= 0x01
+
+	tests := []struct {
+		name    string
+		kek     []byte
+		data    []byte
+		wantErr error
+	}{
+		{"bad kek", kek[:5], wrapped, nil},
+		{"too short", kek, wrapped[:16], ErrCiphertextTooShort},
+		{"not a multiple of 8", kek, wrapped[:20], ErrCiphertextTooShort},
+		{"modified", kek, modified, ErrAuthenticationFailed},
+		{"wrong kek", make([]byte, 16), wrapped, ErrAuthenticationFailed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := UnwrapKey(tt.kek, tt.data)
+			if err == nil {
+				t.Error("UnwrapKey() error = nil, want error")
+				return
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("UnwrapKey() error = %v, want %v", err, tt.wantErr)
+			}
+			if got != nil {
+				t.Errorf("UnwrapKey() = %x, want nil", got)
+			}
+		})
+	}
+}
+
+// nolint: gocognit
+func TestWrapKeyWithPadding(t *testing.T) {
+	// test vectors from https://www.rfc-editor.org/rfc/rfc5649#section-6
+	tests := []struct {
+		name string
+		key  string
+		want string
+	}{
+		{"20 octet key", "c37b7e6492584340bed12207808941155068f738",
+			"138bdeaa9b8fa7fc61f97742e72248ee5ae6ae5360d1ae6a5f54f373fa543b6a"},
+		{"7 octet key", "466f7250617369", "afbeb0f07dfbf5419200f2ccb50bb24f"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kek := mustDecodeHex(t, "5840df6e29b02af1ab493b705bf16ea1ae8338f4dcc176a8")
+			key := mustDecodeHex(t, tt.key)
+
+			got, err := WrapKeyWithPadding(kek, key)
+			if err != nil {
+				t.Fatalf("WrapKeyWithPadding() error = %v", err)
+			}
+			if hex.EncodeToString(got) != tt.want {
+				t.Errorf("WrapKeyWithPadding() = %x, want %s", got, tt.want)
+			}
+
+			unwrapped, err := UnwrapKeyWithPadding(kek, got)
+			if err != nil {
+				t.Fatalf("UnwrapKeyWithPadding() error = %v", err)
+			}
+			if !bytes.Equal(unwrapped, key) {
+				t.Errorf("UnwrapKeyWithPadding() = %x, want %x", unwrapped, key)
+			}
+		})
+	}
+}
+
+func TestWrapKeyWithPaddingErrors(t *testing.T) {
+	kek := mustDecodeHex(t, "5840df6e29b02af1ab493b705bf16ea1ae8338f4dcc176a8")
+
+	if _, err := WrapKeyWithPadding(kek[:5], []byte("key")); err == nil {
+		t.Error("WrapKeyWithPadding() error = nil, want error")
+	}
+	if _, err := WrapKeyWithPadding(kek, nil); !errors.Is(err, ErrNoData) {
+		t.Errorf("WrapKeyWithPadding() error = %v, want %v", err, ErrNoData)
+	}
+}
+
+func TestUnwrapKeyWithPaddingErrors(t *testing.T) {
+	kek := mustDecodeHex(t, "5840df6e29b02af1ab493b705bf16ea1ae8338f4dcc176a8")
+	wrapped := mustDecodeHex(t, "138bdeaa9b8fa7fc61f97742e72248ee5ae6ae5360d1ae6a5f54f373fa543b6a")
+
+	modified := bytes.Clone(wrapped)
+	modified[20] ^= 0x01
+
+	// wrapped without padding, so the initial value does not match RFC 5649
+	unpadded := mustDecodeHex(t, "96778b25ae6ca435f92b5b97c050aed2468ab8a17ad84e5d")
+
+	// a modified key short enough to be wrapped as a single AES block
+	singleBlock, err := WrapKeyWithPadding(kek, []byte("1234567"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	singleBlock[0] ^= 0x01
+
+	tests := []struct {
+		name    string
+		kek     []byte
+		data    []byte
+		wantErr error
+	}{
+		{"bad kek", kek[:5], wrapped, nil},
+		{"too short", kek, wrapped[:8], ErrCiphertextTooShort},
+		{"not a multiple of 8", kek, wrapped[:20], ErrCiphertextTooShort},
+		{"modified", kek, modified, ErrAuthenticationFailed},
+		{"modified single block", kek, singleBlock, ErrAuthenticationFailed},
+		{"not padded", mustDecodeHex(t, "000102030405060708090a0b0c0d0e0f1011121314151617"), unpadded,
+			ErrAuthenticationFailed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := UnwrapKeyWithPadding(tt.kek, tt.data)
+			if err == nil {
+				t.Error("UnwrapKeyWithPadding() error = nil, want error")
+				return
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("UnwrapKeyWithPadding() error = %v, want %v", err, tt.wantErr)
+			}
+			if got != nil {
+				t.Errorf("UnwrapKeyWithPadding() = %x, want nil", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,166 @@
+/* SPDX-License-Identifier: BSD-2-Clause
+
+Copyright (c) 2020-2026, Jef Oliver
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without modification,
+are permitted provided that the following conditions are met:
+
+  1. Redistributions of source code must retain the above copyright notice, this
+     list of conditions and the following disclaimer.
+
+  2. Redistributions in binary form must reproduce the above copyright notice
+     this list of conditions and the following disclaimer in the documentation
+     and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package crypt
+
+import (
+	"bytes"
+	"errors"
+	"math/bits"
+)
+
+// paddingMarker marks the end of data and the start of its padding
+const paddingMarker = 0x80
+
+var (
+	// ErrInvalidPadding is returned when decrypted data does not end with valid padding
+	ErrInvalidPadding = errors.New("invalid padding")
+
+	// PaddingPadme pads data using the Padmé algorithm, which limits the size of the padding to about 12% of the
+	// length of the data while leaking at most O(log log n) bits of information about the length.
+	PaddingPadme Padding = padmePadding{}
+)
+
+// Padded data is laid out as:
+//
+//	data | 0x80 | zero or more 0x00 bytes
+//
+// so the padding can always be removed unambiguously, even when the data itself ends with zero bytes.
+
+type bucketPadding struct {
+	sizes []int
+}
+
+// PaddedSize returns the smallest bucket size the provided length fits in, or the next multiple of the largest bucket
+// size if it fits in none of them
+func (b *bucketPadding) PaddedSize(length int) int {
+	for _, size := range b.sizes {
+		if length <= size {
+			return size
+		}
+	}
+
+	largest := b.sizes[len(b.sizes)-1]
+
+	return (length + largest - 1) / largest * largest
+}
+
+type padmePadding struct{}
+
+// PaddedSize returns the Padmé padded size of the provided length
+func (padmePadding) PaddedSize(length int) int {
+	if length < 2 {
+		return length
+	}
+
+	exponent := bits.Len(uint(length)) - 1
+	lastBits := exponent - bits.Len(uint(exponent))
+	mask := (1 << lastBits) - 1
+
+	return (length + mask) &^ mask
+}
+
+type paddedBlock struct {
+	block   Block
+	padding Padding
+}
+
+// Decrypt decrypts the provided data and removes its padding
+func (p *paddedBlock) Decrypt(data []byte) ([]byte, error) {
+	return p.DecryptWithAAD(data, nil)
+}
+
+// DecryptFromString decrypts data stored in an encoded string and removes its padding
+func (p *paddedBlock) DecryptFromString(data string) ([]byte, error) {
+	return p.DecryptFromStringWithAAD(data, nil)
+}
+
+// DecryptFromStringWithAAD decrypts data stored in an encoded string, authenticating it against the provided
+// additional data, and removes its padding
+func (p *paddedBlock) DecryptFromStringWithAAD(data string, aad []byte) ([]byte, error) {
+	return unpad(p.block.DecryptFromStringWithAAD(data, aad))
+}
+
+// DecryptWithAAD decrypts the provided data, authenticating it against the provided additional data, and removes its
+// padding
+func (p *paddedBlock) DecryptWithAAD(data []byte, aad []byte) ([]byte, error) {
+	return unpad(p.block.DecryptWithAAD(data, aad))
+}
+
+// Encrypt pads and encrypts the provided data
+func (p *paddedBlock) Encrypt(data []byte) ([]byte, error) {
+	return p.EncryptWithAAD(data, nil)
+}
+
+// EncryptToString pads and encrypts the provided data and returns it as an encoded string
+func (p *paddedBlock) EncryptToString(data []byte) (string, error) {
+	return p.EncryptToStringWithAAD(data, nil)
+}
+
+// EncryptToStringWithAAD pads and encrypts the provided data, binding it to the provided additional data, and returns
+// it as an encoded string
+func (p *paddedBlock) EncryptToStringWithAAD(data []byte, aad []byte) (string, error) {
+	if data == nil {
+		return "", ErrNoData
+	}
+
+	return p.block.EncryptToStringWithAAD(p.pad(data), aad)
+}
+
+// EncryptWithAAD pads and encrypts the provided data, binding it to the provided additional data
+func (p *paddedBlock) EncryptWithAAD(data []byte, aad []byte) ([]byte, error) {
+	if data == nil {
+		return nil, ErrNoData
+	}
+
+	return p.block.EncryptWithAAD(p.pad(data), aad)
+}
+
+// pad appends the padding marker and enough zero bytes to reach the padded size of the data
+func (p *paddedBlock) pad(data []byte) []byte {
+	size := max(p.padding.PaddedSize(len(data)+1), len(data)+1)
+
+	ret := make([]byte, size)
+	copy(ret, data)
+	ret[len(data)] = paddingMarker
+
+	return ret
+}
+
+// unpad removes the padding from decrypted data
+func unpad(data []byte, err error) ([]byte, error) {
+	if err != nil {
+		return nil, err
+	}
+
+	trimmed := bytes.TrimRight(data, "\x00")
+	if len(trimmed) > 0 && trimmed[len(trimmed)-1] == paddingMarker {
+		return trimmed[:len(trimmed)-1], nil
+	}
+
+	return nil, ErrInvalidPadding
+}
@@ -0,0 +1,187 @@
+/* SPDX-License-Identifier: BSD-2-Clause
+
+Copyright (c) 2020-2026, Jef Oliver
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without modification,
+are permitted provided that the following conditions are met:
+
+  1. Redistributions of source code must retain the above copyright notice, this
+     list of conditions and the following disclaimer.
+
+  2. Redistributions in binary form must reproduce the above copyright notice
+     this list of conditions and the following disclaimer in the documentation
+     and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package crypt
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func Test_bucketPaddingPaddedSize(t *testing.T) {
+	tester, err := NewBucketPadding(256, 64, 128, 64)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		length int
+		want   int
+	}{
+		{"smallest", 1, 64},
+		{"exact", 64, 64},
+		{"middle", 65, 128},
+		{"largest", 200, 256},
+		{"over largest", 257, 512},
+		{"multiple of largest", 768, 768},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tester.PaddedSize(tt.length); got != tt.want {
+				t.Errorf("bucketPadding.PaddedSize() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_padmePaddingPaddedSize(t *testing.T) {
+	tests := []struct {
+		length int
+		want   int
+	}{
+		{0, 0},
+		{1, 1},
+		{7, 7},
+		{9, 10},
+		{100, 104},
+		{1000, 1024},
+		{1025, 1088},
+		{65537, 67584},
+	}
+
+	for _, tt := range tests {
+		if got := PaddingPadme.PaddedSize(tt.length); got != tt.want {
+			t.Errorf("padmePadding.PaddedSize(%d) = %d, want %d", tt.length, got, tt.want)
+		}
+	}
+}
+
+// nolint: gocognit
+func Test_paddedBlockFullRun(t *testing.T) {
+	buckets, err := NewBucketPadding(32, 64)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		padding Padding
+		data    [][]byte
+	}{
+		{"buckets", buckets, [][]byte{[]byte("github"), []byte("microsoft"), {}, []byte("trailing zeros\x00\x00")}},
+		{"padme", PaddingPadme, [][]byte{bytes.Repeat([]byte("a"), 100), bytes.Repeat([]byte("b"), 103)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inner, err := NewBlock([]byte("testKeyThirtyTwoBytesLong32Bytes"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			tester, err := NewPaddedBlock(inner, tt.padding)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var sizes []int
+			for _, data := range tt.data {
+				encrypted, err := tester.EncryptToStringWithAAD(data, []byte("aad"))
+				if err != nil {
+					t.Fatalf("paddedBlock.EncryptToStringWithAAD() error = %v", err)
+				}
+				sizes = append(sizes, len(encrypted))
+
+				got, err := tester.DecryptFromStringWithAAD(encrypted, []byte("aad"))
+				if err != nil {
+					t.Fatalf("paddedBlock.DecryptFromStringWithAAD() error = %v", err)
+				}
+				if !bytes.Equal(got, data) {
+					t.Errorf("paddedBlock.DecryptFromStringWithAAD() = %q, want %q", got, data)
+				}
+			}
+
+			for _, size := range sizes {
+				if size != sizes[0] {
+					t.Errorf("paddedBlock encrypted sizes = %v, want all equal", sizes)
+					break
+				}
+			}
+		})
+	}
+}
+
+func Test_paddedBlockDecrypt(t *testing.T) {
+	inner, err := NewBlock([]byte("testKeyThirtyTwoBytesLong32Bytes"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tester, err := NewPaddedBlock(inner, PaddingPadme)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	noMarker, err := inner.Encrypt([]byte("no padding marker\x00\x00"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	allZero, err := inner.Encrypt(make([]byte, 8))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		data    []byte
+		wantErr error
+	}{
+		{"no data", nil, ErrNoData},
+		{"no marker", noMarker, ErrInvalidPadding},
+		{"all zero", allZero, ErrInvalidPadding},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tester.Decrypt(tt.data)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("paddedBlock.Decrypt() error = %v, want %v", err, tt.wantErr)
+			}
+			if got != nil {
+				t.Errorf("paddedBlock.Decrypt() = %v, want nil", got)
+			}
+		})
+	}
+
+	if _, err = tester.Encrypt(nil); !errors.Is(err, ErrNoData) {
+		t.Errorf("paddedBlock.Encrypt() error = %v, want %v", err, ErrNoData)
+	}
+	if _, err = tester.EncryptToString(nil); !errors.Is(err, ErrNoData) {
+		t.Errorf("paddedBlock.EncryptToString() error = %v, want %v", err, ErrNoData)
+	}
+}
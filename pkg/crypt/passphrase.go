@@ -0,0 +1,248 @@
+/* SPDX-License-Identifier: BSD-2-Clause
+
+Copyright (c) 2020-2026, Jef Oliver
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without modification,
+are permitted provided that the following conditions are met:
+
+  1. Redistributions of source code must retain the above copyright notice, this
+     list of conditions and the following disclaimer.
+
+  2. Redistributions in binary form must reproduce the above copyright notice
+     this list of conditions and the following disclaimer in the documentation
+     and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package crypt
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"eljef.dev/go/auth/pkg/hash"
+)
+
+const (
+	// PassphraseVersion is the current version of the passphrase encryption format
+	PassphraseVersion uint8 = 1
+
+	// passphraseHeaderSize is the size, in bytes, of the fixed fields of a passphrase header
+	passphraseHeaderSize = 16
+	// passphraseKeySize is the size, in bytes, of the AES-256 key derived from a passphrase
+	passphraseKeySize = 32
+)
+
+var (
+	// ErrPassphraseCost is returned when decrypting data whose key derivation parameters are more expensive than a
+	// Block allows
+	ErrPassphraseCost = errors.New("passphrase key derivation parameters exceed the allowed maximum")
+
+	// passphraseFunctions maps hash functions to their identifiers in a passphrase header
+	passphraseFunctions = map[string]uint8{
+		hash.Argon2I:  1,
+		hash.Argon2ID: 2,
+	}
+
+	// passphraseMagic identifies the start of data encrypted with a passphrase
+	passphraseMagic = []byte("EJP")
+)
+
+// Data encrypted by a passphraseBlock is laid out as:
+//
+//	magic (3 bytes) | version (1 byte) | function (1 byte) | function version (1 byte) | memory (4 bytes) |
+//	iterations (4 bytes) | threads (1 byte) | salt length (1 byte) | salt | envelope
+//
+// The envelope holds the message, encrypted with AES-256-GCM using a key derived from the passphrase and salt. The
+// header is authenticated along with the message.
+
+type passphraseBlock struct {
+	config     hash.Config
+	passphrase string
+}
+
+// Decrypt decrypts the provided data
+func (p *passphraseBlock) Decrypt(data []byte) ([]byte, error) {
+	return p.DecryptWithAAD(data, nil)
+}
+
+// DecryptFromString decrypts data stored in a hex encoded string
+func (p *passphraseBlock) DecryptFromString(data string) ([]byte, error) {
+	return p.DecryptFromStringWithAAD(data, nil)
+}
+
+// DecryptFromStringWithAAD decrypts data stored in a hex encoded string, authenticating it against the provided
+// additional data
+func (p *passphraseBlock) DecryptFromStringWithAAD(data string, aad []byte) ([]byte, error) {
+	dataToDecode, err := decodeString(data, EncodingHex)
+	if err != nil {
+		return nil, err
+	}
+
+	return p.DecryptWithAAD(dataToDecode, aad)
+}
+
+// DecryptWithAAD derives a key from the passphrase, using the parameters stored in the provided data, and decrypts the
+// data with it, authenticating it against the provided additional data. The parameters have not been authenticated
+// when the key is derived, so data whose memory, iterations, or threads exceed those of the Block's config is rejected
+// with ErrPassphraseCost first.
+func (p *passphraseBlock) DecryptWithAAD(data []byte, aad []byte) ([]byte, error) {
+	if len(data) == 0 {
+		return nil, ErrNoData
+	}
+
+	config, salt, headerSize, err := parsePassphraseHeader(data)
+	if err != nil {
+		return nil, err
+	}
+
+	if config.Memory > p.config.Memory || config.Iterations > p.config.Iterations || config.Threads > p.config.Threads {
+		return nil, fmt.Errorf("%w: memory %d, iterations %d, threads %d", ErrPassphraseCost, config.Memory,
+			config.Iterations, config.Threads)
+	}
+
+	key, err := hash.DeriveKey(p.passphrase, salt, config)
+	if err != nil {
+		return nil, err
+	}
+	defer clear(key)
+
+	keyBlock, err := newAESBlock(key, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	return keyBlock.DecryptWithAAD(data[headerSize:], append(bytes.Clone(data[:headerSize]), aad...))
+}
+
+// Encrypt encrypts the provided data with a key derived from the passphrase
+func (p *passphraseBlock) Encrypt(data []byte) ([]byte, error) {
+	return p.EncryptWithAAD(data, nil)
+}
+
+// EncryptToString encrypts the provided data with a key derived from the passphrase and returns it as a hex encoded
+// string
+func (p *passphraseBlock) EncryptToString(data []byte) (string, error) {
+	return p.EncryptToStringWithAAD(data, nil)
+}
+
+// EncryptToStringWithAAD encrypts the provided data with a key derived from the passphrase, binding it to the provided
+// additional data, and returns it as a hex encoded string
+func (p *passphraseBlock) EncryptToStringWithAAD(data []byte, aad []byte) (string, error) {
+	var ret string
+
+	encryptedData, err := p.EncryptWithAAD(data, aad)
+	if err == nil {
+		ret = EncodingHex.EncodeToString(encryptedData)
+	}
+
+	return ret, err
+}
+
+// EncryptWithAAD encrypts the provided data with a key derived from the passphrase and a newly generated salt, binding
+// it to the provided additional data
+func (p *passphraseBlock) EncryptWithAAD(data []byte, aad []byte) ([]byte, error) {
+	if data == nil {
+		return nil, ErrNoData
+	}
+
+	salt := make([]byte, p.config.SaltSize)
+	if _, err := io.ReadFull(rand.Reader, salt); err != nil {
+		return nil, err
+	}
+
+	key, err := hash.DeriveKey(p.passphrase, salt, p.config)
+	if err != nil {
+		return nil, err
+	}
+	defer clear(key)
+
+	keyBlock, err := newAESBlock(key, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	header := newPassphraseHeader(&p.config, salt)
+
+	encryptedData, err := keyBlock.EncryptWithAAD(data, append(bytes.Clone(header), aad...))
+	if err != nil {
+		return nil, err
+	}
+
+	return append(header, encryptedData...), nil
+}
+
+// newPassphraseHeader returns the header storing the provided config and salt
+func newPassphraseHeader(config *hash.Config, salt []byte) []byte {
+	ret := make([]byte, passphraseHeaderSize, passphraseHeaderSize+len(salt))
+	copy(ret, passphraseMagic)
+	ret[3] = PassphraseVersion
+	ret[4] = passphraseFunctions[config.Function]
+	/* #nosec */
+	ret[5] = uint8(config.Version)
+	binary.BigEndian.PutUint32(ret[6:10], config.Memory)
+	binary.BigEndian.PutUint32(ret[10:14], config.Iterations)
+	ret[14] = config.Threads
+	/* #nosec */
+	ret[15] = uint8(len(salt))
+
+	return append(ret, salt...)
+}
+
+// parsePassphraseHeader parses the header of data encrypted with a passphrase, returning the config and salt used to
+// derive the key, and the size of the header
+func parsePassphraseHeader(data []byte) (hash.Config, []byte, int, error) {
+	if len(data) < passphraseHeaderSize {
+		return hash.Config{}, nil, 0, fmt.Errorf("%w: missing passphrase header", ErrCiphertextTooShort)
+	}
+
+	if !bytes.HasPrefix(data, passphraseMagic) {
+		return hash.Config{}, nil, 0, fmt.Errorf("%w: data not encrypted with a passphrase", ErrAuthenticationFailed)
+	}
+
+	if data[3] != PassphraseVersion {
+		return hash.Config{}, nil, 0, fmt.Errorf("%w: unsupported passphrase version: %d", ErrAuthenticationFailed,
+			data[3])
+	}
+
+	config := hash.Config{
+		Iterations: binary.BigEndian.Uint32(data[10:14]),
+		KeySize:    passphraseKeySize,
+		Memory:     binary.BigEndian.Uint32(data[6:10]),
+		Threads:    data[14],
+		Version:    int(data[5]),
+	}
+
+	for function, id := range passphraseFunctions {
+		if id == data[4] {
+			config.Function = function
+		}
+	}
+
+	if config.Function == "" {
+		return hash.Config{}, nil, 0, fmt.Errorf("%w: unsupported passphrase function: %d", ErrAuthenticationFailed,
+			data[4])
+	}
+
+	headerSize := passphraseHeaderSize + int(data[15])
+	if len(data) <= headerSize {
+		return hash.Config{}, nil, 0, fmt.Errorf("%w: missing passphrase salt or data", ErrCiphertextTooShort)
+	}
+
+	return config, data[passphraseHeaderSize:headerSize], headerSize, nil
+}
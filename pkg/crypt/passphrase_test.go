@@ -0,0 +1,214 @@
+/* SPDX-License-Identifier: BSD-2-Clause
+
+Copyright (c) 2020-2026, Jef Oliver
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without modification,
+are permitted provided that the following conditions are met:
+
+  1. Redistributions of source code must retain the above copyright notice, this
+     list of conditions and the following disclaimer.
+
+  2. Redistributions in binary form must reproduce the above copyright notice
+     this list of conditions and the following disclaimer in the documentation
+     and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package crypt
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"testing"
+
+	"eljef.dev/go/auth/pkg/hash"
+)
+
+// testPassphraseConfig returns an inexpensive hash config for testing
+func testPassphraseConfig() hash.Config {
+	return hash.Config{
+		Function:   hash.Argon2ID,
+		Iterations: 1,
+		Memory:     64,
+		SaltSize:   16,
+		Threads:    1,
+		Version:    0x13,
+	}
+}
+
+// nolint: gocognit
+func Test_passphraseBlockFullRun(t *testing.T) {
+	argon2i := testPassphraseConfig()
+	argon2i.Function = hash.Argon2I
+
+	tests := []struct {
+		name             string
+		config           hash.Config
+		decryptPassword  string
+		decryptAAD       []byte
+		wantErr          error
+		wantDecryptError bool
+	}{
+		{"argon2id", testPassphraseConfig(), "correct horse", []byte("aad"), nil, false},
+		{"argon2i", argon2i, "correct horse", []byte("aad"), nil, false},
+		{"wrong passphrase", testPassphraseConfig(), "battery staple", []byte("aad"), ErrAuthenticationFailed, true},
+		{"wrong aad", testPassphraseConfig(), "correct horse", []byte("other"), ErrAuthenticationFailed, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := []byte("this is test data")
+
+			tester, err := NewPassphraseBlock("correct horse", tt.config)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			encryptedData, err := tester.EncryptToStringWithAAD(data, []byte("aad"))
+			if err != nil {
+				t.Fatalf("passphraseBlock.EncryptToStringWithAAD() error = %v", err)
+			}
+
+			// decrypting only requires the passphrase, not the config used to encrypt
+			decrypter, err := NewPassphraseBlock(tt.decryptPassword, hash.GetConfigDefaults())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := decrypter.DecryptFromStringWithAAD(encryptedData, tt.decryptAAD)
+			if (err != nil) != tt.wantDecryptError || !errors.Is(err, tt.wantErr) {
+				t.Errorf("passphraseBlock.DecryptFromStringWithAAD() error = %v, want %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantDecryptError && !bytes.Equal(got, data) {
+				t.Errorf("passphraseBlock.DecryptFromStringWithAAD() = %v, want %v", got, data)
+			}
+		})
+	}
+}
+
+func Test_passphraseBlockDecryptRaisedCost(t *testing.T) {
+	tester, err := NewPassphraseBlock("correct horse", testPassphraseConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	encryptedData, err := tester.Encrypt([]byte("this is test data"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// a 64 MiB, 3000 iteration header would take minutes to derive a key for before authentication failed
+	binary.BigEndian.PutUint32(encryptedData[6:10], 64*1024)
+	binary.BigEndian.PutUint32(encryptedData[10:14], 3000)
+
+	decrypter, err := NewPassphraseBlock("correct horse", hash.GetConfigDefaults())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := decrypter.Decrypt(encryptedData); !errors.Is(err, ErrPassphraseCost) {
+		t.Errorf("passphraseBlock.Decrypt() error = %v, want %v", err, ErrPassphraseCost)
+	}
+}
+
+func Test_passphraseBlockDecryptWithAAD(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    []byte
+		wantErr error
+	}{
+		{"no data", nil, ErrNoData},
+		{"short header", []byte{'E', 'J', 'P', 1, 2}, ErrCiphertextTooShort},
+		{"bad magic", []byte{'E', 'J', 'X', 1, 2, 19, 0, 0, 0, 64, 0, 0, 0, 1, 1, 0}, ErrAuthenticationFailed},
+		{"bad version", []byte{'E', 'J', 'P', 9, 2, 19, 0, 0, 0, 64, 0, 0, 0, 1, 1, 0}, ErrAuthenticationFailed},
+		{"bad function", []byte{'E', 'J', 'P', 1, 9, 19, 0, 0, 0, 64, 0, 0, 0, 1, 1, 0}, ErrAuthenticationFailed},
+		{"short salt", []byte{'E', 'J', 'P', 1, 2, 19, 0, 0, 0, 64, 0, 0, 0, 1, 1, 16, 1}, ErrCiphertextTooShort},
+		{"bad parameters", append([]byte{'E', 'J', 'P', 1, 2, 19, 0, 0, 0, 64, 0, 0, 0, 0, 1, 16},
+			make([]byte, 16)...), nil},
+		{"short envelope", append([]byte{'E', 'J', 'P', 1, 2, 19, 0, 0, 0, 64, 0, 0, 0, 1, 1, 16},
+			make([]byte, 16)...), ErrCiphertextTooShort},
+		{"memory too large", append([]byte{'E', 'J', 'P', 1, 2, 19, 255, 255, 255, 255, 0, 0, 0, 1, 1, 16},
+			make([]byte, 17)...), ErrPassphraseCost},
+		{"iterations too large", append([]byte{'E', 'J', 'P', 1, 2, 19, 0, 0, 0, 64, 0, 0, 11, 184, 1, 16},
+			make([]byte, 17)...), ErrPassphraseCost},
+		{"threads too large", append([]byte{'E', 'J', 'P', 1, 2, 19, 0, 0, 0, 64, 0, 0, 0, 1, 2, 16},
+			make([]byte, 17)...), ErrPassphraseCost},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tester := &passphraseBlock{config: testPassphraseConfig(), passphrase: "correct horse"}
+
+			got, err := tester.DecryptWithAAD(tt.data, nil)
+			if err == nil {
+				t.Errorf("passphraseBlock.DecryptWithAAD() = %v, want error", got)
+				return
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("passphraseBlock.DecryptWithAAD() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_passphraseBlockEncrypt(t *testing.T) {
+	badConfig := testPassphraseConfig()
+	badConfig.Iterations = 0
+
+	tests := []struct {
+		name   string
+		config hash.Config
+		data   []byte
+	}{
+		{"no data", testPassphraseConfig(), nil},
+		{"bad config", badConfig, []byte("this is test data")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tester := &passphraseBlock{config: tt.config, passphrase: "correct horse"}
+			tester.config.KeySize = passphraseKeySize
+
+			if got, err := tester.Encrypt(tt.data); err == nil {
+				t.Errorf("passphraseBlock.Encrypt() = %v, want error", got)
+			}
+		})
+	}
+}
+
+func Test_newPassphraseHeader(t *testing.T) {
+	t.Run("round trip", func(t *testing.T) {
+		config := testPassphraseConfig()
+		config.KeySize = passphraseKeySize
+		salt := []byte("sixteen byte sal")
+
+		header := newPassphraseHeader(&config, salt)
+		want := []byte{'E', 'J', 'P', 1, 2, 19, 0, 0, 0, 64, 0, 0, 0, 1, 1, 16}
+		if !bytes.Equal(header[:passphraseHeaderSize], want) {
+			t.Errorf("newPassphraseHeader() = %v, want %v", header[:passphraseHeaderSize], want)
+		}
+
+		gotConfig, gotSalt, gotSize, err := parsePassphraseHeader(append(header, 1))
+		if err != nil {
+			t.Fatalf("parsePassphraseHeader() error = %v", err)
+		}
+		config.SaltSize = 0
+		if gotConfig != config || !bytes.Equal(gotSalt, salt) || gotSize != len(header) {
+			t.Errorf("parsePassphraseHeader() = %v, %v, %d, want %v, %v, %d", gotConfig, gotSalt, gotSize, config,
+				salt, len(header))
+		}
+	})
+}
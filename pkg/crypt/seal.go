@@ -0,0 +1,199 @@
+/* SPDX-License-Identifier: BSD-2-Clause
+
+Copyright (c) 2020-2026, Jef Oliver
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without modification,
+are permitted provided that the following conditions are met:
+
+  1. Redistributions of source code must retain the above copyright notice, this
+     list of conditions and the following disclaimer.
+
+  2. Redistributions in binary form must reproduce the above copyright notice
+     this list of conditions and the following disclaimer in the documentation
+     and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package crypt
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/gob"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// MigrateFunc converts a value sealed with an old schema version. The decode function decodes the old value into the
+// provided pointer.
+type MigrateFunc func(version uint16, decode func(any) error) (any, error)
+
+// Serialization identifies how Seal serializes values before encrypting them
+type Serialization uint8
+
+const (
+	// SerializationJSON serializes values with encoding/json
+	SerializationJSON Serialization = iota + 1
+	// SerializationGob serializes values with encoding/gob
+	SerializationGob
+
+	// SealVersion is the current version of the sealed value format
+	SealVersion uint8 = 1
+
+	// sealHeaderSize is the size, in bytes, of the header of a sealed value
+	sealHeaderSize = 7
+)
+
+var (
+	// ErrSchemaVersion is returned when opening a value sealed with a different schema version and no migration is
+	// configured
+	ErrSchemaVersion = errors.New("sealed value has an unexpected schema version")
+
+	// sealMagic identifies the start of a sealed value
+	sealMagic = []byte("EJT")
+)
+
+// Sealed values are serialized and laid out as:
+//
+//	magic (3 bytes) | version (1 byte) | serialization (1 byte) | schema version (2 bytes) | serialized value
+//
+// before being encrypted, so the header is authenticated along with the value.
+
+// Open decrypts data sealed by Seal and decodes it into a value of type T
+func Open[T any](b Block, data []byte, config SealConfig) (T, error) {
+	var ret T
+
+	plainText, err := b.DecryptWithAAD(data, config.AAD)
+	if err != nil {
+		return ret, err
+	}
+
+	return openSealed[T](plainText, config)
+}
+
+// OpenFromString decrypts an encoded string produced by SealToString and decodes it into a value of type T
+func OpenFromString[T any](b Block, data string, config SealConfig) (T, error) {
+	var ret T
+
+	plainText, err := b.DecryptFromStringWithAAD(data, config.AAD)
+	if err != nil {
+		return ret, err
+	}
+
+	return openSealed[T](plainText, config)
+}
+
+// Seal serializes and encrypts the provided value with the provided Block
+func Seal[T any](b Block, value T, config SealConfig) ([]byte, error) {
+	data, err := sealValue(value, config)
+	if err != nil {
+		return nil, err
+	}
+
+	return b.EncryptWithAAD(data, config.AAD)
+}
+
+// SealToString serializes and encrypts the provided value with the provided Block, returning it as an encoded string
+func SealToString[T any](b Block, value T, config SealConfig) (string, error) {
+	data, err := sealValue(value, config)
+	if err != nil {
+		return "", err
+	}
+
+	return b.EncryptToStringWithAAD(data, config.AAD)
+}
+
+// decodeSealed decodes a serialized value into dst
+func decodeSealed(serialization Serialization, data []byte, dst any) error {
+	switch serialization {
+	case SerializationJSON:
+		return json.Unmarshal(data, dst)
+	case SerializationGob:
+		return gob.NewDecoder(bytes.NewReader(data)).Decode(dst)
+	default:
+		return fmt.Errorf("unsupported serialization: %d", serialization)
+	}
+}
+
+// openSealed parses the header of a decrypted sealed value and decodes the value, migrating it if needed
+func openSealed[T any](data []byte, config SealConfig) (T, error) {
+	var ret T
+
+	if len(data) < sealHeaderSize || !bytes.Equal(data[:len(sealMagic)], sealMagic) {
+		return ret, fmt.Errorf("%w: not a sealed value", ErrInvalidEncoding)
+	}
+
+	if data[3] != SealVersion {
+		return ret, fmt.Errorf("unsupported sealed value version: %d", data[3])
+	}
+
+	serialization := Serialization(data[4])
+	version := binary.BigEndian.Uint16(data[5:sealHeaderSize])
+	value := data[sealHeaderSize:]
+
+	if version == config.Version {
+		err := decodeSealed(serialization, value, &ret)
+		return ret, err
+	}
+
+	if config.Migrate == nil {
+		return ret, fmt.Errorf("%w: got %d, want %d", ErrSchemaVersion, version, config.Version)
+	}
+
+	migrated, err := config.Migrate(version, func(dst any) error {
+		return decodeSealed(serialization, value, dst)
+	})
+	if err != nil {
+		return ret, err
+	}
+
+	ret, ok := migrated.(T)
+	if !ok {
+		return ret, fmt.Errorf("migration returned %T, want %T", migrated, ret)
+	}
+
+	return ret, nil
+}
+
+// sealValue serializes the provided value and prefixes it with a sealed value header
+func sealValue(value any, config SealConfig) ([]byte, error) {
+	serialization := config.Serialization
+	if serialization == 0 {
+		serialization = SerializationJSON
+	}
+
+	buf := bytes.NewBuffer(make([]byte, 0, sealHeaderSize))
+	buf.Write(sealMagic)
+	buf.WriteByte(SealVersion)
+	buf.WriteByte(byte(serialization))
+	_ = binary.Write(buf, binary.BigEndian, config.Version)
+
+	var err error
+
+	switch serialization {
+	case SerializationJSON:
+		err = json.NewEncoder(buf).Encode(value)
+	case SerializationGob:
+		err = gob.NewEncoder(buf).Encode(value)
+	default:
+		err = fmt.Errorf("unsupported serialization: %d", serialization)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
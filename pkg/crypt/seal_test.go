@@ -0,0 +1,221 @@
+/* SPDX-License-Identifier: BSD-2-Clause
+
+Copyright (c) 2020-2026, Jef Oliver
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without modification,
+are permitted provided that the following conditions are met:
+
+  1. Redistributions of source code must retain the above copyright notice, this
+     list of conditions and the following disclaimer.
+
+  2. Redistributions in binary form must reproduce the above copyright notice
+     this list of conditions and the following disclaimer in the documentation
+     and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package crypt
+
+import (
+	"errors"
+	"testing"
+)
+
+type testSealState struct {
+	Nonce    string
+	Redirect string
+	Version  int
+}
+
+type testSealStateV1 struct {
+	Nonce string
+	URL   string
+}
+
+// newTestSealBlock returns a Block for testing sealed values
+func newTestSealBlock(t *testing.T) Block {
+	t.Helper()
+
+	ret, err := NewBlock([]byte("testKeyThirtyTwoBytesLong32Bytes"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return ret
+}
+
+// nolint: gocognit
+func TestSealFullRun(t *testing.T) {
+	tests := []struct {
+		name   string
+		config SealConfig
+	}{
+		{"default", SealConfig{}},
+		{"json", SealConfig{Serialization: SerializationJSON, Version: 2}},
+		{"gob", SealConfig{Serialization: SerializationGob, Version: 2}},
+		{"aad", SealConfig{AAD: []byte("oauth_state")}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tester := newTestSealBlock(t)
+			want := testSealState{Nonce: "abc123", Redirect: "/home", Version: 3}
+
+			sealed, err := Seal(tester, want, tt.config)
+			if err != nil {
+				t.Fatalf("Seal() error = %v", err)
+			}
+
+			got, err := Open[testSealState](tester, sealed, tt.config)
+			if err != nil {
+				t.Fatalf("Open() error = %v", err)
+			}
+			if got != want {
+				t.Errorf("Open() = %v, want %v", got, want)
+			}
+
+			sealedString, err := SealToString(tester, want, tt.config)
+			if err != nil {
+				t.Fatalf("SealToString() error = %v", err)
+			}
+
+			got, err = OpenFromString[testSealState](tester, sealedString, tt.config)
+			if err != nil {
+				t.Fatalf("OpenFromString() error = %v", err)
+			}
+			if got != want {
+				t.Errorf("OpenFromString() = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+// nolint: gocognit
+func TestOpenMigrate(t *testing.T) {
+	migrate := func(version uint16, decode func(any) error) (any, error) {
+		if version != 1 {
+			return nil, ErrSchemaVersion
+		}
+
+		var old testSealStateV1
+		if err := decode(&old); err != nil {
+			return nil, err
+		}
+
+		return testSealState{Nonce: old.Nonce, Redirect: old.URL, Version: 2}, nil
+	}
+
+	badMigrate := func(uint16, func(any) error) (any, error) {
+		return "not a state", nil
+	}
+
+	tests := []struct {
+		name      string
+		migrate   MigrateFunc
+		want      testSealState
+		wantErr   bool
+		wantErrIs error
+	}{
+		{"migrated", migrate, testSealState{Nonce: "abc123", Redirect: "/home", Version: 2}, false, nil},
+		{"no migration", nil, testSealState{}, true, ErrSchemaVersion},
+		{"wrong type", badMigrate, testSealState{}, true, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tester := newTestSealBlock(t)
+
+			sealed, err := Seal(tester, testSealStateV1{Nonce: "abc123", URL: "/home"},
+				SealConfig{Serialization: SerializationGob, Version: 1})
+			if err != nil {
+				t.Fatalf("Seal() error = %v", err)
+			}
+
+			got, err := Open[testSealState](tester, sealed,
+				SealConfig{Migrate: tt.migrate, Serialization: SerializationJSON, Version: 2})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Open() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErrIs != nil && !errors.Is(err, tt.wantErrIs) {
+				t.Errorf("Open() error = %v, want %v", err, tt.wantErrIs)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("Open() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOpenErrors(t *testing.T) {
+	tester := newTestSealBlock(t)
+
+	notSealed, err := tester.Encrypt([]byte("plain data"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	badSerialization, err := tester.Encrypt([]byte("EJT\x01\x09\x00\x00{}"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	badVersion, err := tester.Encrypt([]byte("EJT\x02\x01\x00\x00{}"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sealed, err := Seal(tester, testSealState{}, SealConfig{AAD: []byte("state")})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		data    []byte
+		config  SealConfig
+		wantErr error
+	}{
+		{"no data", nil, SealConfig{}, ErrNoData},
+		{"not sealed", notSealed, SealConfig{}, ErrInvalidEncoding},
+		{"wrong aad", sealed, SealConfig{AAD: []byte("other")}, ErrAuthenticationFailed},
+		{"bad serialization", badSerialization, SealConfig{}, nil},
+		{"bad version", badVersion, SealConfig{}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Open[testSealState](tester, tt.data, tt.config)
+			if err == nil {
+				t.Error("Open() error = nil, want error")
+				return
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("Open() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSealErrors(t *testing.T) {
+	tester := newTestSealBlock(t)
+
+	if _, err := Seal(tester, testSealState{}, SealConfig{Serialization: 9}); err == nil {
+		t.Error("Seal() error = nil, want error")
+	}
+	if _, err := Seal(tester, func() {}, SealConfig{}); err == nil {
+		t.Error("Seal() error = nil, want error")
+	}
+	if _, err := SealToString(tester, func() {}, SealConfig{}); err == nil {
+		t.Error("SealToString() error = nil, want error")
+	}
+}
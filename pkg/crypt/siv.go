@@ -0,0 +1,234 @@
+/* SPDX-License-Identifier: BSD-2-Clause
+
+Copyright (c) 2020-2026, Jef Oliver
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without modification,
+are permitted provided that the following conditions are met:
+
+  1. Redistributions of source code must retain the above copyright notice, this
+     list of conditions and the following disclaimer.
+
+  2. Redistributions in binary form must reproduce the above copyright notice
+     this list of conditions and the following disclaimer in the documentation
+     and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package crypt
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/subtle"
+	"fmt"
+)
+
+// sivSize is the size, in bytes, of the synthetic IV prepended to data encrypted with AES-SIV
+const sivSize = aes.BlockSize
+
+// AES-SIV is implemented per https://www.rfc-editor.org/rfc/rfc5297, with AES-CMAC implemented per
+// https://www.rfc-editor.org/rfc/rfc4493.
+
+type sivBlock struct {
+	ctrAES cipher.Block
+	macAES cipher.Block
+}
+
+// Decrypt decrypts the provided data
+func (s *sivBlock) Decrypt(data []byte) ([]byte, error) {
+	return s.DecryptWithAAD(data, nil)
+}
+
+// DecryptFromString decrypts data stored in a hex encoded string
+func (s *sivBlock) DecryptFromString(data string) ([]byte, error) {
+	return s.DecryptFromStringWithAAD(data, nil)
+}
+
+// DecryptFromStringWithAAD decrypts data stored in a hex encoded string, authenticating it against the provided
+// additional data
+func (s *sivBlock) DecryptFromStringWithAAD(data string, aad []byte) ([]byte, error) {
+	dataToDecode, err := decodeString(data, EncodingHex)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.DecryptWithAAD(dataToDecode, aad)
+}
+
+// DecryptWithAAD decrypts the provided data, authenticating it against the provided additional data
+func (s *sivBlock) DecryptWithAAD(data []byte, aad []byte) ([]byte, error) {
+	if len(data) == 0 {
+		return nil, ErrNoData
+	}
+
+	return s.open(data, sivAdditionalData(aad)...)
+}
+
+// Encrypt deterministically encrypts the provided data
+func (s *sivBlock) Encrypt(data []byte) ([]byte, error) {
+	return s.EncryptWithAAD(data, nil)
+}
+
+// EncryptToString deterministically encrypts the provided data and returns it as a hex encoded string
+func (s *sivBlock) EncryptToString(data []byte) (string, error) {
+	return s.EncryptToStringWithAAD(data, nil)
+}
+
+// EncryptToStringWithAAD deterministically encrypts the provided data, binding it to the provided additional data, and
+// returns it as a hex encoded string
+func (s *sivBlock) EncryptToStringWithAAD(data []byte, aad []byte) (string, error) {
+	var ret string
+
+	encryptedData, err := s.EncryptWithAAD(data, aad)
+	if err == nil {
+		ret = EncodingHex.EncodeToString(encryptedData)
+	}
+
+	return ret, err
+}
+
+// EncryptWithAAD deterministically encrypts the provided data, binding it to the provided additional data
+func (s *sivBlock) EncryptWithAAD(data []byte, aad []byte) ([]byte, error) {
+	if data == nil {
+		return nil, ErrNoData
+	}
+
+	return s.seal(data, sivAdditionalData(aad)...), nil
+}
+
+// cmac returns the AES-CMAC of the provided data
+func (s *sivBlock) cmac(data []byte) []byte {
+	k1 := make([]byte, aes.BlockSize)
+	s.macAES.Encrypt(k1, k1)
+	k1 = sivDouble(k1)
+
+	// the final block is xored with k1 when complete, and with k2 when padded
+	complete := len(data) > 0 && len(data)%aes.BlockSize == 0
+	lastStart := len(data) - aes.BlockSize
+	if !complete {
+		lastStart = len(data) - len(data)%aes.BlockSize
+	}
+
+	last := make([]byte, aes.BlockSize)
+	if complete {
+		subtle.XORBytes(last, data[lastStart:], k1)
+	} else {
+		copy(last, data[lastStart:])
+		last[len(data)-lastStart] = 0x80
+		subtle.XORBytes(last, last, sivDouble(k1))
+	}
+
+	mac := make([]byte, aes.BlockSize)
+	for i := 0; i < lastStart; i += aes.BlockSize {
+		subtle.XORBytes(mac, mac, data[i:i+aes.BlockSize])
+		s.macAES.Encrypt(mac, mac)
+	}
+
+	subtle.XORBytes(mac, mac, last)
+	s.macAES.Encrypt(mac, mac)
+
+	return mac
+}
+
+// ctrStream returns the CTR mode stream for the provided synthetic IV
+func (s *sivBlock) ctrStream(v []byte) cipher.Stream {
+	q := make([]byte, sivSize)
+	copy(q, v)
+	q[8] &= 0x7f
+	q[12] &= 0x7f
+
+	return cipher.NewCTR(s.ctrAES, q)
+}
+
+// open verifies and decrypts data encrypted with seal, using the provided additional data
+func (s *sivBlock) open(data []byte, ad ...[]byte) ([]byte, error) {
+	if len(data) < sivSize {
+		return nil, fmt.Errorf("%w: missing synthetic iv", ErrCiphertextTooShort)
+	}
+
+	v := data[:sivSize]
+	ret := make([]byte, len(data)-sivSize)
+	s.ctrStream(v).XORKeyStream(ret, data[sivSize:])
+
+	if subtle.ConstantTimeCompare(s.s2v(append(ad, ret)...), v) != 1 {
+		clear(ret)
+		return nil, ErrAuthenticationFailed
+	}
+
+	return ret, nil
+}
+
+// s2v computes the S2V function over the provided components, the last of which is the plaintext
+func (s *sivBlock) s2v(components ...[]byte) []byte {
+	d := s.cmac(make([]byte, aes.BlockSize))
+
+	for _, component := range components[:len(components)-1] {
+		d = sivDouble(d)
+		subtle.XORBytes(d, d, s.cmac(component))
+	}
+
+	last := components[len(components)-1]
+	if len(last) >= aes.BlockSize {
+		t := make([]byte, len(last))
+		copy(t, last)
+		subtle.XORBytes(t[len(t)-aes.BlockSize:], t[len(t)-aes.BlockSize:], d)
+
+		return s.cmac(t)
+	}
+
+	t := make([]byte, aes.BlockSize)
+	copy(t, last)
+	t[len(last)] = 0x80
+	subtle.XORBytes(t, t, sivDouble(d))
+
+	return s.cmac(t)
+}
+
+// seal encrypts the provided data, using the provided additional data, returning the synthetic IV followed by the
+// encrypted data
+func (s *sivBlock) seal(data []byte, ad ...[]byte) []byte {
+	v := s.s2v(append(ad, data)...)
+
+	ret := make([]byte, sivSize+len(data))
+	copy(ret, v)
+	s.ctrStream(v).XORKeyStream(ret[sivSize:], data)
+
+	return ret
+}
+
+// sivAdditionalData returns the additional data strings for S2V. Empty additional data is treated as no additional
+// data, matching the behavior of the AEAD ciphers used by Block.
+func sivAdditionalData(aad []byte) [][]byte {
+	if len(aad) == 0 {
+		return nil
+	}
+
+	return [][]byte{aad}
+}
+
+// sivDouble multiplies the provided block by x in GF(2^128), returning a new block
+func sivDouble(data []byte) []byte {
+	ret := make([]byte, aes.BlockSize)
+
+	var carry byte
+	for i := aes.BlockSize - 1; i >= 0; i-- {
+		ret[i] = data[i]<<1 | carry
+		carry = data[i] >> 7
+	}
+
+	// constant time reduction by x^128 + x^7 + x^2 + x + 1
+	ret[aes.BlockSize-1] ^= 0x87 & -carry
+
+	return ret
+}
@@ -0,0 +1,231 @@
+/* SPDX-License-Identifier: BSD-2-Clause
+
+Copyright (c) 2020-2026, Jef Oliver
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without modification,
+are permitted provided that the following conditions are met:
+
+  1. Redistributions of source code must retain the above copyright notice, this
+     list of conditions and the following disclaimer.
+
+  2. Redistributions in binary form must reproduce the above copyright notice
+     this list of conditions and the following disclaimer in the documentation
+     and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package crypt
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"testing"
+)
+
+// mustDecodeHex decodes a hex string, failing the test on error
+func mustDecodeHex(t *testing.T, data string) []byte {
+	t.Helper()
+
+	ret, err := hex.DecodeString(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return ret
+}
+
+// newTestSIVBlock returns a sivBlock using the provided hex encoded key
+func newTestSIVBlock(t *testing.T, key string) *sivBlock {
+	t.Helper()
+
+	ret, err := NewDeterministicBlock(mustDecodeHex(t, key))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return ret.(*sivBlock)
+}
+
+func Test_sivBlockCMAC(t *testing.T) {
+	// test vectors from https://www.rfc-editor.org/rfc/rfc4493#section-4
+	tests := []struct {
+		name string
+		data string
+		want string
+	}{
+		{"empty", "", "bb1d6929e95937287fa37d129b756746"},
+		{"one block", "6bc1bee22e409f96e93d7e117393172a", "070a16b46b4d4144f79bdd9dd04a287c"},
+		{"partial block",
+			"6bc1bee22e409f96e93d7e117393172aae2d8a571e03ac9c9eb76fac45af8e5130c81c46a35ce411",
+			"dfa66747de9ae63030ca32611497c827"},
+		{"four blocks",
+			"6bc1bee22e409f96e93d7e117393172aae2d8a571e03ac9c9eb76fac45af8e5130c81c46a35ce411e5fbc1191a0a52ef" +
+				"f69f2445df4f9b17ad2b417be66c3710",
+			"51f0bebf7e3b9d92fc49741779363cfe"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tester := newTestSIVBlock(t, "2b7e151628aed2a6abf7158809cf4f3c2b7e151628aed2a6abf7158809cf4f3c")
+
+			if got := tester.cmac(mustDecodeHex(t, tt.data)); hex.EncodeToString(got) != tt.want {
+				t.Errorf("sivBlock.cmac() = %x, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+// nolint: gocognit
+func Test_sivBlockVectors(t *testing.T) {
+	// test vectors from https://www.rfc-editor.org/rfc/rfc5297#appendix-A
+	tests := []struct {
+		name      string
+		key       string
+		ad        []string
+		plaintext string
+		want      string
+	}{
+		{"deterministic authenticated encryption",
+			"fffefdfcfbfaf9f8f7f6f5f4f3f2f1f0f0f1f2f3f4f5f6f7f8f9fafbfcfdfeff",
+			[]string{"101112131415161718191a1b1c1d1e1f2021222324252627"},
+			"112233445566778899aabbccddee",
+			"85632d07c6e8f37f950acd320a2ecc9340c02b9690c4dc04daef7f6afe5c"},
+		{"nonce based authenticated encryption",
+			"7f7e7d7c7b7a79787776757473727170404142434445464748494a4b4c4d4e4f",
+			[]string{
+				"00112233445566778899aabbccddeeffdeaddadadeaddadaffeeddccbbaa99887766554433221100",
+				"102030405060708090a0",
+				"09f911029d74e35bd84156c5635688c0",
+			},
+			"7468697320697320736f6d6520706c61696e7465787420746f20656e6372797074207573696e67205349562d414553",
+			"7bdb6e3b432667eb06f4d14bff2fbd0fcb900f2fddbe404326601965c889bf17dba77ceb094fa663b7a3f748ba8af829" +
+				"ea64ad544a272e9c485b62a3fd5c0d"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tester := newTestSIVBlock(t, tt.key)
+
+			ad := make([][]byte, 0, len(tt.ad))
+			for _, component := range tt.ad {
+				ad = append(ad, mustDecodeHex(t, component))
+			}
+			plaintext := mustDecodeHex(t, tt.plaintext)
+
+			got := tester.seal(plaintext, ad...)
+			if hex.EncodeToString(got) != tt.want {
+				t.Errorf("sivBlock.seal() = %x, want %s", got, tt.want)
+				return
+			}
+
+			opened, err := tester.open(got, ad...)
+			if err != nil {
+				t.Fatalf("sivBlock.open() error = %v", err)
+			}
+			if !bytes.Equal(opened, plaintext) {
+				t.Errorf("sivBlock.open() = %x, want %x", opened, plaintext)
+			}
+		})
+	}
+}
+
+// nolint: gocognit
+func Test_sivBlockFullRun(t *testing.T) {
+	t.Run("deterministic", func(t *testing.T) {
+		data := []byte("user@example.com")
+
+		tester, err := NewDeterministicBlock([]byte("testKeyThirtyTwoBytesLong32Bytes"))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		first, err := tester.EncryptToStringWithAAD(data, []byte("users.email"))
+		if err != nil {
+			t.Fatalf("sivBlock.EncryptToStringWithAAD() error = %v", err)
+		}
+		second, err := tester.EncryptToStringWithAAD(data, []byte("users.email"))
+		if err != nil {
+			t.Fatalf("sivBlock.EncryptToStringWithAAD() error = %v", err)
+		}
+		if first != second {
+			t.Errorf("sivBlock.EncryptToStringWithAAD() = %s and %s, want equal", first, second)
+		}
+
+		other, err := tester.EncryptToString(data)
+		if err != nil {
+			t.Fatalf("sivBlock.EncryptToString() error = %v", err)
+		}
+		if other == first {
+			t.Error("sivBlock.EncryptToString() without aad matches encryption with aad")
+		}
+
+		got, err := tester.DecryptFromStringWithAAD(first, []byte("users.email"))
+		if err != nil {
+			t.Fatalf("sivBlock.DecryptFromStringWithAAD() error = %v", err)
+		}
+		if !bytes.Equal(got, data) {
+			t.Errorf("sivBlock.DecryptFromStringWithAAD() = %v, want %v", got, data)
+		}
+
+		if got, err = tester.DecryptFromString(other); err != nil || !bytes.Equal(got, data) {
+			t.Errorf("sivBlock.DecryptFromString() = %v, %v, want %v", got, err, data)
+		}
+
+		if _, err = tester.DecryptFromStringWithAAD(first, []byte("users.name")); !errors.Is(err,
+			ErrAuthenticationFailed) {
+			t.Errorf("sivBlock.DecryptFromStringWithAAD() error = %v, want %v", err, ErrAuthenticationFailed)
+		}
+	})
+}
+
+func Test_sivBlockDecrypt(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    []byte
+		wantErr error
+	}{
+		{"no data", nil, ErrNoData},
+		{"too short", []byte{1, 2, 3}, ErrCiphertextTooShort},
+		{"modified", mustDecodeHex(t, "85632d07c6e8f37f950acd320a2ecc9340c02b9690c4dc04daef7f6afe5d"),
+			ErrAuthenticationFailed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tester := newTestSIVBlock(t, "fffefdfcfbfaf9f8f7f6f5f4f3f2f1f0f0f1f2f3f4f5f6f7f8f9fafbfcfdfeff")
+
+			got, err := tester.Decrypt(tt.data)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("sivBlock.Decrypt() error = %v, want %v", err, tt.wantErr)
+			}
+			if got != nil {
+				t.Errorf("sivBlock.Decrypt() = %v, want nil", got)
+			}
+		})
+	}
+}
+
+func Test_sivBlockEncrypt(t *testing.T) {
+	t.Run("no data", func(t *testing.T) {
+		tester := newTestSIVBlock(t, "fffefdfcfbfaf9f8f7f6f5f4f3f2f1f0f0f1f2f3f4f5f6f7f8f9fafbfcfdfeff")
+
+		if _, err := tester.Encrypt(nil); !errors.Is(err, ErrNoData) {
+			t.Errorf("sivBlock.Encrypt() error = %v, want %v", err, ErrNoData)
+		}
+		if _, err := tester.EncryptToString(nil); !errors.Is(err, ErrNoData) {
+			t.Errorf("sivBlock.EncryptToString() error = %v, want %v", err, ErrNoData)
+		}
+	})
+}
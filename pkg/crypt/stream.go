@@ -0,0 +1,290 @@
+/* SPDX-License-Identifier: BSD-2-Clause
+
+Copyright (c) 2020-2026, Jef Oliver
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without modification,
+are permitted provided that the following conditions are met:
+
+  1. Redistributions of source code must retain the above copyright notice, this
+     list of conditions and the following disclaimer.
+
+  2. Redistributions in binary form must reproduce the above copyright notice
+     this list of conditions and the following disclaimer in the documentation
+     and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package crypt
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hkdf"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+)
+
+const (
+	// StreamChunkSize is the size, in bytes, of the plaintext held in each chunk of an encrypted stream
+	StreamChunkSize = 64 * 1024
+
+	// StreamVersion is the current version of the encrypted stream format
+	StreamVersion uint8 = 1
+
+	// streamHeaderSize is the size, in bytes, of the magic, version and salt fields of an encrypted stream
+	streamHeaderSize = 4 + streamSaltSize
+	// streamInfo is the HKDF info used when deriving a stream key
+	streamInfo = "eljef.dev/go/auth/crypt stream"
+	// streamSaltSize is the size, in bytes, of the random salt used to derive a stream key
+	streamSaltSize = 32
+)
+
+// streamMagic identifies the start of an encrypted stream
+var streamMagic = []byte("EJS")
+
+// Encrypted streams are laid out as:
+//
+//	magic (3 bytes) | version (1 byte) | salt (32 bytes) | chunk | chunk | ... | final chunk
+//
+// Each stream is encrypted with an AES-256-GCM key derived, via HKDF-SHA256, from the provided key and a random salt,
+// so nonces never repeat across streams encrypted with the same key. Each chunk holds up to StreamChunkSize bytes of
+// plaintext, and is sealed with a nonce made up of the chunk counter and a flag marking the final chunk. Reordering
+// chunks breaks the counter, and truncating the stream loses the final chunk, so both are detected when decrypting.
+
+type decryptReader struct {
+	aead    cipher.AEAD
+	buf     []byte
+	chunk   []byte
+	counter uint64
+	done    bool
+	err     error
+	header  []byte
+	r       *bufio.Reader
+}
+
+// Read reads and decrypts data from the underlying stream
+func (d *decryptReader) Read(p []byte) (int, error) {
+	for len(d.buf) == 0 {
+		if d.err != nil {
+			return 0, d.err
+		}
+
+		if d.done {
+			return 0, io.EOF
+		}
+
+		d.err = d.readChunk()
+	}
+
+	n := copy(p, d.buf)
+	d.buf = d.buf[n:]
+
+	return n, nil
+}
+
+// readChunk reads and decrypts the next chunk from the underlying stream
+func (d *decryptReader) readChunk() error {
+	final := false
+
+	n, err := io.ReadFull(d.r, d.chunk)
+	switch {
+	case errors.Is(err, io.EOF):
+		return fmt.Errorf("%w: encrypted stream truncated", ErrAuthenticationFailed)
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		final = true
+	case err != nil:
+		return err
+	default:
+		if _, err = d.r.Peek(1); errors.Is(err, io.EOF) {
+			final = true
+		}
+	}
+
+	nonce, err := streamNonce(d.counter, final)
+	if err != nil {
+		return err
+	}
+
+	d.buf, err = d.aead.Open(d.buf[:0], nonce, d.chunk[:n], d.header)
+	if err != nil {
+		return ErrAuthenticationFailed
+	}
+
+	d.counter++
+	d.done = final
+
+	return nil
+}
+
+type encryptWriter struct {
+	aead    cipher.AEAD
+	buf     []byte
+	closed  bool
+	counter uint64
+	header  []byte
+	w       io.Writer
+}
+
+// Close encrypts and writes the final chunk of the stream. Close does not close the underlying writer.
+func (e *encryptWriter) Close() error {
+	if e.closed {
+		return nil
+	}
+
+	e.closed = true
+
+	return e.flush(true)
+}
+
+// flush encrypts and writes the buffered plaintext as a chunk
+func (e *encryptWriter) flush(final bool) error {
+	nonce, err := streamNonce(e.counter, final)
+	if err != nil {
+		return err
+	}
+
+	if _, err = e.w.Write(e.aead.Seal(nil, nonce, e.buf, e.header)); err != nil {
+		return err
+	}
+
+	e.buf = e.buf[:0]
+	e.counter++
+
+	return nil
+}
+
+// Write encrypts the provided data, writing full chunks to the underlying writer as they are filled
+func (e *encryptWriter) Write(p []byte) (int, error) {
+	var n int
+
+	if e.closed {
+		return 0, errors.New("write to closed encrypted stream")
+	}
+
+	for len(p) > 0 {
+		// A full chunk is only written once more data arrives, so the final chunk is always written by Close.
+		if len(e.buf) == StreamChunkSize {
+			if err := e.flush(false); err != nil {
+				return n, err
+			}
+		}
+
+		take := min(StreamChunkSize-len(e.buf), len(p))
+		e.buf = append(e.buf, p[:take]...)
+		p = p[take:]
+		n += take
+	}
+
+	return n, nil
+}
+
+// newStreamAEAD returns the AES-256-GCM AEAD for a stream, derived from the provided key and stream header
+func newStreamAEAD(key []byte, header []byte) (cipher.AEAD, error) {
+	if _, err := aes.NewCipher(key); err != nil {
+		return nil, err
+	}
+
+	streamKey, err := hkdf.Key(sha256.New, key, header[4:], streamInfo, 32)
+	if err != nil {
+		return nil, err
+	}
+
+	rAES, err := aes.NewCipher(streamKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(rAES)
+}
+
+// newDecryptReader reads the stream header from r and returns a reader that decrypts the rest of the stream
+func newDecryptReader(r io.Reader, key []byte) (*decryptReader, error) {
+	header := make([]byte, streamHeaderSize)
+	if _, err := io.ReadFull(r, header); err != nil {
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return nil, fmt.Errorf("%w: missing encrypted stream header", ErrCiphertextTooShort)
+		}
+
+		return nil, err
+	}
+
+	if !bytes.HasPrefix(header, streamMagic) {
+		return nil, fmt.Errorf("%w: data is not an encrypted stream", ErrAuthenticationFailed)
+	}
+
+	if header[3] != StreamVersion {
+		return nil, fmt.Errorf("%w: unsupported encrypted stream version: %d", ErrAuthenticationFailed, header[3])
+	}
+
+	aead, err := newStreamAEAD(key, header)
+	if err != nil {
+		return nil, err
+	}
+
+	return &decryptReader{
+		aead:   aead,
+		chunk:  make([]byte, StreamChunkSize+aead.Overhead()),
+		header: header,
+		r:      bufio.NewReader(r),
+	}, nil
+}
+
+// newEncryptWriter writes a stream header to w and returns a writer that encrypts the rest of the stream
+func newEncryptWriter(w io.Writer, key []byte) (*encryptWriter, error) {
+	header := make([]byte, streamHeaderSize)
+	copy(header, streamMagic)
+	header[3] = StreamVersion
+
+	if _, err := io.ReadFull(rand.Reader, header[4:]); err != nil {
+		return nil, err
+	}
+
+	aead, err := newStreamAEAD(key, header)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err = w.Write(header); err != nil {
+		return nil, err
+	}
+
+	return &encryptWriter{
+		aead:   aead,
+		buf:    make([]byte, 0, StreamChunkSize),
+		header: header,
+		w:      w,
+	}, nil
+}
+
+// streamNonce returns the nonce for the chunk at the provided position in the stream
+func streamNonce(counter uint64, final bool) ([]byte, error) {
+	if counter == math.MaxUint64 {
+		return nil, errors.New("encrypted stream too long")
+	}
+
+	nonce := make([]byte, 12)
+	binary.BigEndian.PutUint64(nonce[3:11], counter)
+	if final {
+		nonce[11] = 1
+	}
+
+	return nonce, nil
+}
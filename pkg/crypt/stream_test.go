@@ -0,0 +1,223 @@
+/* SPDX-License-Identifier: BSD-2-Clause
+
+Copyright (c) 2020-2026, Jef Oliver
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without modification,
+are permitted provided that the following conditions are met:
+
+  1. Redistributions of source code must retain the above copyright notice, this
+     list of conditions and the following disclaimer.
+
+  2. Redistributions in binary form must reproduce the above copyright notice
+     this list of conditions and the following disclaimer in the documentation
+     and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package crypt
+
+import (
+	"bytes"
+	"crypto/rand"
+	"errors"
+	"io"
+	"testing"
+	"testing/iotest"
+)
+
+// encryptTestStream encrypts data as a stream with the provided key
+func encryptTestStream(t *testing.T, key []byte, data []byte) []byte {
+	t.Helper()
+
+	out := &bytes.Buffer{}
+	writer, err := NewEncryptWriter(out, key)
+	if err != nil {
+		t.Fatalf("NewEncryptWriter() error = %v", err)
+	}
+
+	// write in uneven pieces to exercise chunk buffering
+	for len(data) > 0 {
+		n := min(len(data), 1000)
+		if _, err = writer.Write(data[:n]); err != nil {
+			t.Fatalf("encryptWriter.Write() error = %v", err)
+		}
+		data = data[n:]
+	}
+
+	if err = writer.Close(); err != nil {
+		t.Fatalf("encryptWriter.Close() error = %v", err)
+	}
+
+	return out.Bytes()
+}
+
+// nolint: gocognit
+func Test_streamFullRun(t *testing.T) {
+	tests := []struct {
+		name string
+		size int
+	}{
+		{"empty", 0},
+		{"small", 17},
+		{"one byte short of a chunk", StreamChunkSize - 1},
+		{"exactly one chunk", StreamChunkSize},
+		{"one byte over a chunk", StreamChunkSize + 1},
+		{"multiple chunks", 3*StreamChunkSize + 5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key := []byte("testKeySixteen16")
+			data := make([]byte, tt.size)
+			if _, err := rand.Read(data); err != nil {
+				t.Fatal(err)
+			}
+
+			encrypted := encryptTestStream(t, key, data)
+
+			reader, err := NewDecryptReader(bytes.NewReader(encrypted), key)
+			if err != nil {
+				t.Fatalf("NewDecryptReader() error = %v", err)
+			}
+
+			got, err := io.ReadAll(reader)
+			if err != nil {
+				t.Fatalf("decryptReader.Read() error = %v", err)
+			}
+			if !bytes.Equal(got, data) {
+				t.Errorf("decryptReader.Read() got %d bytes, want %d bytes", len(got), len(data))
+			}
+		})
+	}
+}
+
+// nolint: gocognit
+func Test_streamTampering(t *testing.T) {
+	key := []byte("testKeySixteen16")
+	data := make([]byte, 2*StreamChunkSize+100)
+	encChunkSize := StreamChunkSize + 16
+
+	tests := []struct {
+		name   string
+		key    []byte
+		tamper func([]byte) []byte
+	}{
+		{"wrong key", []byte("otherKeySixteen1"), func(b []byte) []byte { return b }},
+		{"truncated final chunk", key, func(b []byte) []byte { return b[:streamHeaderSize+2*encChunkSize] }},
+		{"truncated mid chunk", key, func(b []byte) []byte { return b[:streamHeaderSize+encChunkSize+10] }},
+		{"header only", key, func(b []byte) []byte { return b[:streamHeaderSize] }},
+		{"reordered chunks", key, func(b []byte) []byte {
+			ret := append([]byte{}, b[:streamHeaderSize]...)
+			ret = append(ret, b[streamHeaderSize+encChunkSize:streamHeaderSize+2*encChunkSize]...)
+			ret = append(ret, b[streamHeaderSize:streamHeaderSize+encChunkSize]...)
+			return append(ret, b[streamHeaderSize+2*encChunkSize:]...)
+		}},
+		{"modified salt", key, func(b []byte) []byte {
+			b[5] ^= 0xff
+			return b
+		}},
+		{"modified chunk", key, func(b []byte) []byte {
+			b[streamHeaderSize+encChunkSize+1] ^= 0xff
+			return b
+		}},
+		{"appended data", key, func(b []byte) []byte { return append(b, 1, 2, 3) }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			encrypted := tt.tamper(encryptTestStream(t, key, data))
+
+			reader, err := NewDecryptReader(bytes.NewReader(encrypted), tt.key)
+			if err != nil {
+				t.Fatalf("NewDecryptReader() error = %v", err)
+			}
+
+			if _, err = io.ReadAll(reader); err == nil {
+				t.Error("decryptReader.Read() error = nil, want error")
+			}
+		})
+	}
+}
+
+func Test_encryptWriterClose(t *testing.T) {
+	t.Run("write after close", func(t *testing.T) {
+		writer, err := NewEncryptWriter(io.Discard, []byte("testKeySixteen16"))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if err = writer.Close(); err != nil {
+			t.Fatalf("encryptWriter.Close() error = %v", err)
+		}
+		if err = writer.Close(); err != nil {
+			t.Errorf("encryptWriter.Close() second call error = %v", err)
+		}
+		if _, err = writer.Write([]byte("data")); err == nil {
+			t.Error("encryptWriter.Write() error = nil, want error")
+		}
+	})
+}
+
+func Test_newDecryptReader(t *testing.T) {
+	key := []byte("testKeySixteen16")
+	readErr := errors.New("testing error")
+	salt := make([]byte, streamSaltSize)
+
+	tests := []struct {
+		name    string
+		r       io.Reader
+		wantErr error
+	}{
+		{"empty", bytes.NewReader(nil), ErrCiphertextTooShort},
+		{"short header", bytes.NewReader([]byte{'E', 'J', 'S', StreamVersion}), ErrCiphertextTooShort},
+		{"read error", iotest.ErrReader(readErr), readErr},
+		{"bad magic", bytes.NewReader(append([]byte{'E', 'J', 'X', StreamVersion}, salt...)), ErrAuthenticationFailed},
+		{"bad version", bytes.NewReader(append([]byte{'E', 'J', 'S', 9}, salt...)), ErrAuthenticationFailed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := newDecryptReader(tt.r, key); !errors.Is(err, tt.wantErr) {
+				t.Errorf("newDecryptReader() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_streamNonce(t *testing.T) {
+	tests := []struct {
+		name    string
+		counter uint64
+		final   bool
+		want    []byte
+		wantErr bool
+	}{
+		{"first", 0, false, []byte{0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0}, false},
+		{"final", 258, true, []byte{0, 0, 0, 0, 0, 0, 0, 0, 0, 1, 2, 1}, false},
+		{"overflow", 1<<64 - 1, false, nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := streamNonce(tt.counter, tt.final)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("streamNonce() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !bytes.Equal(got, tt.want) {
+				t.Errorf("streamNonce() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
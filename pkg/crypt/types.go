@@ -27,11 +27,110 @@ OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
 
 package crypt
 
-// Block is an interface that wraps an AES Cipher to be used for encryption
-// and decryption of data.
+// Block is an interface that wraps an AEAD Cipher, such as AES-GCM or
+// XChaCha20-Poly1305, to be used for encryption and decryption of data.
+//
+// The WithAAD variants bind the ciphertext to additional authenticated data, such as a user ID or cookie name. The
+// same additional data must be provided to decrypt the ciphertext, allowing a ciphertext created for one context to
+// be rejected when replayed into another.
+//
+// The string functions use hex encoding, unless the Block was created with another Encoding.
+//
+// Blocks returned by this package are safe for concurrent use by multiple goroutines. The underlying cipher is built
+// once when the Block is created, so a single Block should be shared rather than created per call.
 type Block interface {
-	Decrypt([]byte) ([]byte, error)           // Decrypt decrypts the provided data
-	DecryptFromString(string) ([]byte, error) // DecryptFromString decrypts data stored in a hex encoded string
-	Encrypt([]byte) ([]byte, error)           // Encrypt encrypts the provided data
-	EncryptToString([]byte) (string, error)   // EncryptToString encrypts the provided data and returns it as a nex encoded string
+	Decrypt([]byte) ([]byte, error)                          // Decrypt decrypts the provided data
+	DecryptFromString(string) ([]byte, error)                // DecryptFromString decrypts data stored in an encoded string
+	DecryptFromStringWithAAD(string, []byte) ([]byte, error) // DecryptFromStringWithAAD decrypts encoded data, authenticating additional data
+	DecryptWithAAD([]byte, []byte) ([]byte, error)           // DecryptWithAAD decrypts the provided data, authenticating additional data
+	Encrypt([]byte) ([]byte, error)                          // Encrypt encrypts the provided data
+	EncryptToString([]byte) (string, error)                  // EncryptToString encrypts the provided data and returns it as an encoded string
+	EncryptToStringWithAAD([]byte, []byte) (string, error)   // EncryptToStringWithAAD encrypts data bound to additional data, returning an encoded string
+	EncryptWithAAD([]byte, []byte) ([]byte, error)           // EncryptWithAAD encrypts the provided data, binding it to additional data
+}
+
+// Keyring is a Block that holds multiple keys, allowing keys to be rotated without invalidating data encrypted with
+// older keys.
+//
+// Data is always encrypted with the primary key, and the ID of the key used is recorded in the Envelope. Data can be
+// decrypted with any key still held by the keyring.
+type Keyring interface {
+	Block
+
+	AddKey(uint32, []byte) error // AddKey adds a key, identified by the provided ID, to the keyring
+	Keys() []uint32              // Keys returns the IDs of all keys held by the keyring, sorted in ascending order
+	Primary() uint32             // Primary returns the ID of the key used for encryption
+	Promote(uint32) error        // Promote makes the key identified by the provided ID the primary key
+	Retire(uint32) error         // Retire removes the key identified by the provided ID from the keyring
+}
+
+// Encoding is an interface that encodes encrypted data to, and decodes encrypted data from, strings.
+type Encoding interface {
+	DecodeString(string) ([]byte, error) // DecodeString decodes the provided string
+	EncodeToString([]byte) string        // EncodeToString encodes the provided data to a string
+}
+
+// KeyEncryptionKey is an interface that wraps and unwraps data encryption keys. Implementations may hold the key
+// encryption key locally, or delegate to an external key management service.
+type KeyEncryptionKey interface {
+	UnwrapKey([]byte) ([]byte, error) // UnwrapKey decrypts a data key wrapped by WrapKey
+	WrapKey([]byte) ([]byte, error)   // WrapKey encrypts the provided data key
+}
+
+// KeyDerivation is an interface that derives purpose labelled keys from a single master key via HKDF-SHA256.
+//
+// Keys derived for different labels are independent, so the leak of a key derived for one purpose does not expose the
+// master key, or the key derived for any other purpose.
+type KeyDerivation interface {
+	Block(string) (Block, error)           // Block returns an AES-256-GCM Block using the key derived for the provided label
+	DeriveKey(string, int) ([]byte, error) // DeriveKey derives a key of the provided size for the provided label
+}
+
+// DeterministicBlock is an interface that wraps AES-SIV, a deterministic, nonce misuse resistant, cipher to be used
+// for encryption and decryption of data that must support equality lookups, such as email addresses stored in a
+// database.
+//
+// Encrypting the same data with the same key and additional data always produces the same encrypted data. This leaks
+// whether two encrypted values hold the same data, along with the length of the data. A DeterministicBlock should only
+// be used when equality lookups are required. Otherwise, a Block should be used.
+type DeterministicBlock interface {
+	Decrypt([]byte) ([]byte, error)                          // Decrypt decrypts the provided data
+	DecryptFromString(string) ([]byte, error)                // DecryptFromString decrypts data stored in a hex encoded string
+	DecryptFromStringWithAAD(string, []byte) ([]byte, error) // DecryptFromStringWithAAD decrypts hex encoded data, authenticating additional data
+	DecryptWithAAD([]byte, []byte) ([]byte, error)           // DecryptWithAAD decrypts the provided data, authenticating additional data
+	Encrypt([]byte) ([]byte, error)                          // Encrypt deterministically encrypts the provided data
+	EncryptToString([]byte) (string, error)                  // EncryptToString deterministically encrypts data and returns it as a hex encoded string
+	EncryptToStringWithAAD([]byte, []byte) (string, error)   // EncryptToStringWithAAD deterministically encrypts data bound to additional data, returning a hex encoded string
+	EncryptWithAAD([]byte, []byte) ([]byte, error)           // EncryptWithAAD deterministically encrypts data, binding it to additional data
+}
+
+// Identity is an X25519 private key, used to decrypt data encrypted to its Recipient. Implementations may hold the
+// private key outside of the process, such as in a hardware token, as only key agreement is required.
+type Identity interface {
+	Recipient() Recipient                // Recipient returns the Recipient data must be encrypted to for the Identity
+	SharedSecret([]byte) ([]byte, error) // SharedSecret performs X25519 key agreement with the provided public key
+	String() string                      // String returns the Identity encoded for storage
+}
+
+// Padding is a policy that decides how much data is padded before encryption, hiding its exact length.
+type Padding interface {
+	PaddedSize(int) int // PaddedSize returns the size data of the provided length is padded to
+}
+
+// Recipient is an X25519 public key that data can be encrypted to.
+type Recipient interface {
+	PublicKey() []byte // PublicKey returns the raw X25519 public key
+	String() string    // String returns the Recipient encoded for sharing
+}
+
+// SealConfig configures how Seal and Open serialize and encrypt typed values.
+//
+// Version is a schema version sealed with the value. When Open finds a value sealed with a different version, it
+// calls Migrate, if set, to convert the old value. The decode function provided to Migrate decodes the old value into
+// the provided pointer, which would usually be a type describing the old schema.
+type SealConfig struct {
+	AAD           []byte        // AAD is additional authenticated data the value is bound to
+	Migrate       MigrateFunc   // Migrate converts values sealed with other schema versions
+	Serialization Serialization // Serialization defaults to SerializationJSON
+	Version       uint16        // Version is the schema version of sealed values
 }
@@ -0,0 +1,383 @@
+/* SPDX-License-Identifier: BSD-2-Clause
+
+Copyright (c) 2020-2026, Jef Oliver
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without modification,
+are permitted provided that the following conditions are met:
+
+  1. Redistributions of source code must retain the above copyright notice, this
+     list of conditions and the following disclaimer.
+
+  2. Redistributions in binary form must reproduce the above copyright notice
+     this list of conditions and the following disclaimer in the documentation
+     and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package crypt
+
+import (
+	"bytes"
+	"crypto/ecdh"
+	"crypto/hkdf"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+)
+
+const (
+	// RecipientVersion is the current version of the recipient encryption format
+	RecipientVersion uint8 = 1
+
+	// fileKeySize is the size, in bytes, of the AES-256 key generated for each message encrypted to recipients
+	fileKeySize = 32
+	// identityPrefix prefixes an encoded Identity
+	identityPrefix = "x25519-secret:"
+	// recipientHeaderSize is the size, in bytes, of the magic, version and stanza count fields of data encrypted to
+	// recipients
+	recipientHeaderSize = 6
+	// recipientPrefix prefixes an encoded Recipient
+	recipientPrefix = "x25519:"
+	// stanzaSize is the size, in bytes, of a stanza: an ephemeral public key followed by the file key wrapped in an
+	// AES-GCM envelope
+	stanzaSize = x25519KeySize + envelopeHeaderSize + 12 + fileKeySize + 16
+	// x25519KeySize is the size, in bytes, of X25519 public and private keys
+	x25519KeySize = 32
+)
+
+var (
+	// ErrNoIdentity is returned when decrypting data that was not encrypted to the Identity of the Block
+	ErrNoIdentity = errors.New("data was not encrypted to this identity")
+
+	// recipientMagic identifies the start of data encrypted to recipients
+	recipientMagic = []byte("EJR")
+	// stanzaInfo is the HKDF info used to derive the keys that wrap file keys
+	stanzaInfo = "eljef.dev/go/auth/crypt x25519"
+)
+
+// Data encrypted by a recipientBlock is laid out as:
+//
+//	magic (3 bytes) | version (1 byte) | stanza count (2 bytes) | stanzas | envelope
+//
+// Each message is encrypted with a new file key. For each recipient, a stanza holds a new ephemeral X25519 public key
+// and the file key, wrapped with AES-256-GCM using a key derived with HKDF-SHA256 from the shared secret of the
+// ephemeral key and the recipient. Stanzas do not identify their recipient. The envelope holds the message,
+// encrypted with AES-256-GCM using the file key, and the header and stanzas are authenticated along with it.
+
+type recipientBlock struct {
+	identity   Identity
+	recipients []Recipient
+}
+
+// Decrypt decrypts the provided data with the identity of the block
+func (r *recipientBlock) Decrypt(data []byte) ([]byte, error) {
+	return r.DecryptWithAAD(data, nil)
+}
+
+// DecryptFromString decrypts data stored in a hex encoded string with the identity of the block
+func (r *recipientBlock) DecryptFromString(data string) ([]byte, error) {
+	return r.DecryptFromStringWithAAD(data, nil)
+}
+
+// DecryptFromStringWithAAD decrypts data stored in a hex encoded string with the identity of the block,
+// authenticating it against the provided additional data
+func (r *recipientBlock) DecryptFromStringWithAAD(data string, aad []byte) ([]byte, error) {
+	dataToDecode, err := decodeString(data, EncodingHex)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.DecryptWithAAD(dataToDecode, aad)
+}
+
+// DecryptWithAAD unwraps the file key from the stanza for the identity of the block and decrypts the data with it,
+// authenticating it against the provided additional data
+func (r *recipientBlock) DecryptWithAAD(data []byte, aad []byte) ([]byte, error) {
+	if len(data) == 0 {
+		return nil, ErrNoData
+	}
+
+	if r.identity == nil {
+		return nil, errors.New("no identity to decrypt with")
+	}
+
+	if len(data) < recipientHeaderSize {
+		return nil, fmt.Errorf("%w: missing recipient header", ErrCiphertextTooShort)
+	}
+
+	if !bytes.HasPrefix(data, recipientMagic) || data[3] != RecipientVersion {
+		return nil, fmt.Errorf("%w: data not encrypted to recipients", ErrAuthenticationFailed)
+	}
+
+	headerSize := recipientHeaderSize + int(binary.BigEndian.Uint16(data[4:recipientHeaderSize]))*stanzaSize
+	if len(data) <= headerSize {
+		return nil, fmt.Errorf("%w: missing stanzas or data", ErrCiphertextTooShort)
+	}
+
+	fileKey, err := r.unwrapFileKey(data[recipientHeaderSize:headerSize])
+	if err != nil {
+		return nil, err
+	}
+	defer clear(fileKey)
+
+	dataBlock, err := newAESBlock(fileKey, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	return dataBlock.DecryptWithAAD(data[headerSize:], append(bytes.Clone(data[:headerSize]), aad...))
+}
+
+// Encrypt encrypts the provided data to the recipients of the block
+func (r *recipientBlock) Encrypt(data []byte) ([]byte, error) {
+	return r.EncryptWithAAD(data, nil)
+}
+
+// EncryptToString encrypts the provided data to the recipients of the block and returns it as a hex encoded string
+func (r *recipientBlock) EncryptToString(data []byte) (string, error) {
+	return r.EncryptToStringWithAAD(data, nil)
+}
+
+// EncryptToStringWithAAD encrypts the provided data to the recipients of the block, binding it to the provided
+// additional data, and returns it as a hex encoded string
+func (r *recipientBlock) EncryptToStringWithAAD(data []byte, aad []byte) (string, error) {
+	var ret string
+
+	encryptedData, err := r.EncryptWithAAD(data, aad)
+	if err == nil {
+		ret = EncodingHex.EncodeToString(encryptedData)
+	}
+
+	return ret, err
+}
+
+// EncryptWithAAD encrypts the provided data with a newly generated file key, binding it to the provided additional
+// data, and prepends a stanza wrapping the file key for each recipient of the block
+func (r *recipientBlock) EncryptWithAAD(data []byte, aad []byte) ([]byte, error) {
+	if data == nil {
+		return nil, ErrNoData
+	}
+
+	if len(r.recipients) == 0 {
+		return nil, errors.New("no recipients to encrypt to")
+	}
+
+	fileKey := make([]byte, fileKeySize)
+	defer clear(fileKey)
+
+	if _, err := io.ReadFull(rand.Reader, fileKey); err != nil {
+		return nil, err
+	}
+
+	header := make([]byte, recipientHeaderSize, recipientHeaderSize+len(r.recipients)*stanzaSize)
+	copy(header, recipientMagic)
+	header[3] = RecipientVersion
+	/* #nosec */
+	binary.BigEndian.PutUint16(header[4:], uint16(len(r.recipients)))
+
+	for _, recipient := range r.recipients {
+		stanza, err := wrapFileKey(fileKey, recipient)
+		if err != nil {
+			return nil, err
+		}
+
+		header = append(header, stanza...)
+	}
+
+	dataBlock, err := newAESBlock(fileKey, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	encryptedData, err := dataBlock.EncryptWithAAD(data, append(bytes.Clone(header), aad...))
+	if err != nil {
+		return nil, err
+	}
+
+	return append(header, encryptedData...), nil
+}
+
+// unwrapFileKey returns the file key from the first stanza that can be opened by the identity of the block
+func (r *recipientBlock) unwrapFileKey(stanzas []byte) ([]byte, error) {
+	publicKey := r.identity.Recipient().PublicKey()
+
+	for stanza := range slices.Chunk(stanzas, stanzaSize) {
+		ephemeralKey := stanza[:x25519KeySize]
+
+		sharedSecret, err := r.identity.SharedSecret(ephemeralKey)
+		if err != nil {
+			continue
+		}
+
+		wrapBlock, err := newStanzaBlock(sharedSecret, ephemeralKey, publicKey)
+		clear(sharedSecret)
+		if err != nil {
+			return nil, err
+		}
+
+		if fileKey, err := wrapBlock.Decrypt(stanza[x25519KeySize:]); err == nil {
+			return fileKey, nil
+		}
+	}
+
+	return nil, ErrNoIdentity
+}
+
+type x25519Identity struct {
+	key *ecdh.PrivateKey
+}
+
+// Recipient returns the Recipient data must be encrypted to for the identity
+func (x *x25519Identity) Recipient() Recipient {
+	return &x25519Recipient{key: x.key.PublicKey()}
+}
+
+// SharedSecret performs X25519 key agreement with the provided public key
+func (x *x25519Identity) SharedSecret(publicKey []byte) ([]byte, error) {
+	key, err := ecdh.X25519().NewPublicKey(publicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return x.key.ECDH(key)
+}
+
+// String returns the identity encoded for storage
+func (x *x25519Identity) String() string {
+	return identityPrefix + EncodingBase64URL.EncodeToString(x.key.Bytes())
+}
+
+type x25519Recipient struct {
+	key *ecdh.PublicKey
+}
+
+// PublicKey returns the raw X25519 public key
+func (x *x25519Recipient) PublicKey() []byte {
+	return x.key.Bytes()
+}
+
+// String returns the recipient encoded for sharing
+func (x *x25519Recipient) String() string {
+	return recipientPrefix + EncodingBase64URL.EncodeToString(x.key.Bytes())
+}
+
+// GenerateIdentity returns a new random X25519 Identity
+func GenerateIdentity() (Identity, error) {
+	key, err := ecdh.X25519().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+
+	return &x25519Identity{key: key}, nil
+}
+
+// ParseIdentity decodes an Identity encoded by its String function
+func ParseIdentity(data string) (Identity, error) {
+	raw, err := decodeX25519Key(data, identityPrefix)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := ecdh.X25519().NewPrivateKey(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	return &x25519Identity{key: key}, nil
+}
+
+// ParseRecipient decodes a Recipient encoded by its String function
+func ParseRecipient(data string) (Recipient, error) {
+	raw, err := decodeX25519Key(data, recipientPrefix)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := ecdh.X25519().NewPublicKey(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	return &x25519Recipient{key: key}, nil
+}
+
+// decodeX25519Key decodes a raw X25519 key from a string with the provided prefix
+func decodeX25519Key(data, prefix string) ([]byte, error) {
+	encoded, ok := strings.CutPrefix(strings.TrimSpace(data), prefix)
+	if !ok {
+		return nil, fmt.Errorf("%w: missing %q prefix", ErrInvalidEncoding, prefix)
+	}
+
+	raw, err := decodeString(encoded, EncodingBase64URL)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(raw) != x25519KeySize {
+		return nil, fmt.Errorf("%w: %d bytes", ErrInvalidKeySize, len(raw))
+	}
+
+	return raw, nil
+}
+
+// newStanzaBlock returns the Block that wraps a file key for the recipient of a stanza
+func newStanzaBlock(sharedSecret, ephemeralKey, publicKey []byte) (*block, error) {
+	salt := append(bytes.Clone(ephemeralKey), publicKey...)
+
+	key, err := hkdf.Key(sha256.New, sharedSecret, salt, stanzaInfo, fileKeySize)
+	if err != nil {
+		return nil, err
+	}
+	defer clear(key)
+
+	return newAESBlock(key, 0)
+}
+
+// wrapFileKey returns a stanza wrapping the file key for the provided recipient
+func wrapFileKey(fileKey []byte, recipient Recipient) ([]byte, error) {
+	ephemeral, err := ecdh.X25519().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+
+	publicKey, err := ecdh.X25519().NewPublicKey(recipient.PublicKey())
+	if err != nil {
+		return nil, err
+	}
+
+	sharedSecret, err := ephemeral.ECDH(publicKey)
+	if err != nil {
+		return nil, err
+	}
+	defer clear(sharedSecret)
+
+	ephemeralKey := ephemeral.PublicKey().Bytes()
+
+	wrapBlock, err := newStanzaBlock(sharedSecret, ephemeralKey, publicKey.Bytes())
+	if err != nil {
+		return nil, err
+	}
+
+	wrappedKey, err := wrapBlock.Encrypt(fileKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(ephemeralKey, wrappedKey...), nil
+}
@@ -0,0 +1,254 @@
+/* SPDX-License-Identifier: BSD-2-Clause
+
+Copyright (c) 2020-2026, Jef Oliver
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without modification,
+are permitted provided that the following conditions are met:
+
+  1. Redistributions of source code must retain the above copyright notice, this
+     list of conditions and the following disclaimer.
+
+  2. Redistributions in binary form must reproduce the above copyright notice
+     this list of conditions and the following disclaimer in the documentation
+     and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package crypt
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"testing"
+)
+
+// mustGenerateIdentity returns a new Identity, failing the test on error
+func mustGenerateIdentity(t *testing.T) Identity {
+	t.Helper()
+
+	ret, err := GenerateIdentity()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return ret
+}
+
+// nolint: gocognit
+func Test_recipientBlockFullRun(t *testing.T) {
+	data := []byte("this is a backup shared between services")
+	identities := []Identity{mustGenerateIdentity(t), mustGenerateIdentity(t), mustGenerateIdentity(t)}
+
+	recipients := make([]Recipient, 0, len(identities))
+	for _, identity := range identities {
+		recipients = append(recipients, identity.Recipient())
+	}
+
+	encrypter, err := NewRecipientBlock(nil, recipients...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	encrypted, err := encrypter.EncryptToStringWithAAD(data, []byte("backup"))
+	if err != nil {
+		t.Fatalf("recipientBlock.EncryptToStringWithAAD() error = %v", err)
+	}
+
+	for i, identity := range identities {
+		decrypter, err := NewRecipientBlock(identity)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		got, err := decrypter.DecryptFromStringWithAAD(encrypted, []byte("backup"))
+		if err != nil {
+			t.Errorf("recipientBlock.DecryptFromStringWithAAD() identity %d error = %v", i, err)
+			continue
+		}
+		if !bytes.Equal(got, data) {
+			t.Errorf("recipientBlock.DecryptFromStringWithAAD() identity %d = %s, want %s", i, got, data)
+		}
+
+		if _, err = decrypter.DecryptFromStringWithAAD(encrypted, []byte("other")); !errors.Is(err,
+			ErrAuthenticationFailed) {
+			t.Errorf("recipientBlock.DecryptFromStringWithAAD() error = %v, want %v", err, ErrAuthenticationFailed)
+		}
+	}
+
+	stranger, err := NewRecipientBlock(mustGenerateIdentity(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = stranger.DecryptFromStringWithAAD(encrypted, []byte("backup")); !errors.Is(err, ErrNoIdentity) {
+		t.Errorf("recipientBlock.DecryptFromStringWithAAD() error = %v, want %v", err, ErrNoIdentity)
+	}
+}
+
+func Test_recipientBlockDecrypt(t *testing.T) {
+	identity := mustGenerateIdentity(t)
+
+	tester, err := NewRecipientBlock(identity, identity.Recipient())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	encrypted, err := tester.Encrypt([]byte("this is test data"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	moreStanzas := bytes.Clone(encrypted)
+	moreStanzas[5]++
+
+	modified := bytes.Clone(encrypted)
+	modified[len(modified)-1] ^= 0xff
+
+	tests := []struct {
+		name    string
+		data    []byte
+		wantErr error
+	}{
+		{"no data", nil, ErrNoData},
+		{"short header", []byte("EJR"), ErrCiphertextTooShort},
+		{"not recipient data", []byte("EJK\x01\x00\x00data"), ErrAuthenticationFailed},
+		{"missing stanzas", encrypted[:recipientHeaderSize+stanzaSize], ErrCiphertextTooShort},
+		{"changed stanza count", moreStanzas, ErrCiphertextTooShort},
+		{"modified", modified, ErrAuthenticationFailed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tester.Decrypt(tt.data)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("recipientBlock.Decrypt() error = %v, want %v", err, tt.wantErr)
+			}
+			if got != nil {
+				t.Errorf("recipientBlock.Decrypt() = %v, want nil", got)
+			}
+		})
+	}
+}
+
+func Test_recipientBlockMissingKeys(t *testing.T) {
+	identity := mustGenerateIdentity(t)
+
+	encryptOnly, err := NewRecipientBlock(nil, identity.Recipient())
+	if err != nil {
+		t.Fatal(err)
+	}
+	decryptOnly, err := NewRecipientBlock(identity)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	encrypted, err := encryptOnly.Encrypt([]byte("this is test data"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = encryptOnly.Decrypt(encrypted); err == nil {
+		t.Error("recipientBlock.Decrypt() without an identity error = nil, want error")
+	}
+	if _, err = decryptOnly.Encrypt([]byte("this is test data")); err == nil {
+		t.Error("recipientBlock.Encrypt() without recipients error = nil, want error")
+	}
+	if _, err = encryptOnly.Encrypt(nil); !errors.Is(err, ErrNoData) {
+		t.Errorf("recipientBlock.Encrypt() error = %v, want %v", err, ErrNoData)
+	}
+}
+
+func Test_x25519IdentitySharedSecret(t *testing.T) {
+	// test vector from https://www.rfc-editor.org/rfc/rfc7748#section-6.1
+	alice, err := ParseIdentity(identityPrefix + EncodingBase64URL.EncodeToString(
+		mustDecodeHex(t, "77076d0a7318a57d3c16c17251b26645df4c2f87ebc0992ab177fba51db92c2a")))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := hex.EncodeToString(alice.Recipient().PublicKey()); got !=
+		"8520f0098930a754748b7ddcb43ef75a0dbf3a0d26381af4eba4a98eaa9b4e6a" {
+		t.Errorf("x25519Identity.Recipient() = %s", got)
+	}
+
+	got, err := alice.SharedSecret(mustDecodeHex(t, "de9edb7d7b7dc1b4d35b61c2ece435373f8343c85b78674dadfc7e146f882b4f"))
+	if err != nil {
+		t.Fatalf("x25519Identity.SharedSecret() error = %v", err)
+	}
+	if hex.EncodeToString(got) != "4a5d9d5ba4ce2de1728e3bf480350f25e07e21c947d19e3376f09b3c1e161742" {
+		t.Errorf("x25519Identity.SharedSecret() = %x", got)
+	}
+
+	if _, err = alice.SharedSecret(make([]byte, x25519KeySize)); err == nil {
+		t.Error("x25519Identity.SharedSecret() with a low order point error = nil, want error")
+	}
+}
+
+func TestParseIdentity(t *testing.T) {
+	identity := mustGenerateIdentity(t)
+
+	tests := []struct {
+		name    string
+		data    string
+		wantErr bool
+	}{
+		{"good", identity.String(), false},
+		{"recipient", identity.Recipient().String(), true},
+		{"no prefix", "AAAA", true},
+		{"bad encoding", identityPrefix + "!!!", true},
+		{"bad size", identityPrefix + "AAAA", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseIdentity(tt.data)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseIdentity() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && got.String() != identity.String() {
+				t.Errorf("ParseIdentity() = %s, want %s", got, identity)
+			}
+		})
+	}
+}
+
+func TestParseRecipient(t *testing.T) {
+	recipient := mustGenerateIdentity(t).Recipient()
+
+	tests := []struct {
+		name    string
+		data    string
+		wantErr bool
+	}{
+		{"good", recipient.String(), false},
+		{"whitespace", " " + recipient.String() + "\n", false},
+		{"no prefix", "AAAA", true},
+		{"bad encoding", recipientPrefix + "!!!", true},
+		{"bad size", recipientPrefix + "AAAA", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseRecipient(tt.data)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseRecipient() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && !bytes.Equal(got.PublicKey(), recipient.PublicKey()) {
+				t.Errorf("ParseRecipient() = %s, want %s", got, recipient)
+			}
+		})
+	}
+}
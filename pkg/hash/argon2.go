@@ -0,0 +1,57 @@
+/* SPDX-License-Identifier: BSD-2-Clause
+
+Copyright (c) 2020-2026, Jef Oliver
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without modification,
+are permitted provided that the following conditions are met:
+
+  1. Redistributions of source code must retain the above copyright notice, this
+     list of conditions and the following disclaimer.
+
+  2. Redistributions in binary form must reproduce the above copyright notice
+     this list of conditions and the following disclaimer in the documentation
+     and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package hash
+
+// argon2Hasher is the Hasher for the argon2i and argon2id functions
+type argon2Hasher struct{}
+
+// Decode decodes an encoded argon2 hash
+func (argon2Hasher) Decode(data string) (Info, error) {
+	return decodeArgon2(data)
+}
+
+// Generate hashes data with argon2, using the provided config
+func (argon2Hasher) Generate(data string, config Config) (Info, error) {
+	return generateArgon2(data, config)
+}
+
+// NeedsRehash reports whether an argon2 hash was created with parameters that differ from the provided config
+func (argon2Hasher) NeedsRehash(info Info, current Config) bool {
+	return info.Function != current.Function ||
+		info.Version != current.Version ||
+		info.Memory != current.Memory ||
+		info.Iterations != current.Iterations ||
+		info.Threads != current.Threads ||
+		info.KeySize != current.KeySize ||
+		info.SaltSize != current.SaltSize
+}
+
+// Verify reports whether data matches an argon2 hash
+func (argon2Hasher) Verify(data string, info Info) bool {
+	return matchesArgon2(data, info)
+}
@@ -0,0 +1,140 @@
+/* SPDX-License-Identifier: BSD-2-Clause
+
+Copyright (c) 2020-2026, Jef Oliver
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without modification,
+are permitted provided that the following conditions are met:
+
+  1. Redistributions of source code must retain the above copyright notice, this
+     list of conditions and the following disclaimer.
+
+  2. Redistributions in binary form must reproduce the above copyright notice
+     this list of conditions and the following disclaimer in the documentation
+     and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package hash
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	// bcryptHashSize is the size, in characters, of the salt and hash section of a bcrypt hash
+	bcryptHashSize = 53
+	// bcryptMaxDataSize is the largest size, in bytes, of data bcrypt can hash
+	bcryptMaxDataSize = 72
+	// bcryptSaltSize is the size, in characters, of the encoded salt of a bcrypt hash
+	bcryptSaltSize = 22
+)
+
+// bcryptEncoding is the base64 variant used by bcrypt
+var bcryptEncoding = base64.NewEncoding("./ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789").
+	WithPadding(base64.NoPadding)
+
+// bcryptHasher is the Hasher for bcrypt hashes, in the $2a$, $2b$ and $2y$ variants. Decoded bcrypt hashes use the
+// Bcrypt function, with the variant kept in the encoded hash.
+type bcryptHasher struct{}
+
+// Decode decodes an encoded bcrypt hash
+func (bcryptHasher) Decode(data string) (Info, error) {
+	parts := strings.Split(data, "$")
+	if len(parts) != 4 || parts[0] != "" || len(parts[3]) != bcryptHashSize {
+		return Info{}, errors.New(errInvalidFormat)
+	}
+
+	switch parts[1] {
+	case "2a", "2b", "2y":
+	default:
+		return Info{}, fmt.Errorf("unknown encode function: %s", parts[1])
+	}
+
+	cost, err := bcrypt.Cost([]byte(data))
+	if err != nil {
+		return Info{}, errors.New(errInvalidConfig)
+	}
+
+	salt, err := bcryptEncoding.DecodeString(parts[3][:bcryptSaltSize])
+	if err != nil {
+		return Info{}, errors.New("invalid hash salt")
+	}
+
+	hash, err := bcryptEncoding.DecodeString(parts[3][bcryptSaltSize:])
+	if err != nil {
+		return Info{}, errors.New("invalid hash body")
+	}
+
+	// The salt and hash are decoded from fixed size sections, so their sizes always fit.
+	return Info{
+		Config: Config{
+			Cost:     cost,
+			Function: Bcrypt,
+			/* #nosec */
+			KeySize: uint32(len(hash)),
+			/* #nosec */
+			SaltSize: uint32(len(salt)),
+		},
+		Encoded: data,
+		Hash:    hash,
+		Salt:    salt,
+	}, nil
+}
+
+// Generate hashes data with bcrypt, using the Cost of the provided config. Data longer than 72 bytes cannot be hashed
+// by bcrypt and is rejected. The $2a$, $2b$ and $2y$ identifiers are only registered to decode hashes, so configs must
+// use the Bcrypt function, which generated hashes are decoded with.
+func (b bcryptHasher) Generate(data string, config Config) (Info, error) {
+	if data == "" {
+		return Info{}, errors.New("empty data")
+	}
+
+	if config.Function != Bcrypt {
+		return Info{}, fmt.Errorf("bcrypt hashes must be generated with the %s function: %s", Bcrypt, config.Function)
+	}
+
+	if len(data) > bcryptMaxDataSize {
+		return Info{}, fmt.Errorf("data is longer than %d bytes", bcryptMaxDataSize)
+	}
+
+	if config.Cost < bcrypt.MinCost || config.Cost > bcrypt.MaxCost {
+		return Info{}, fmt.Errorf("invalid bcrypt cost: %d", config.Cost)
+	}
+
+	encoded, err := bcrypt.GenerateFromPassword([]byte(data), config.Cost)
+	if err != nil {
+		return Info{}, err
+	}
+
+	return b.Decode(string(encoded))
+}
+
+// NeedsRehash reports whether a bcrypt hash was created with a cost that differs from the provided config
+func (bcryptHasher) NeedsRehash(info Info, current Config) bool {
+	return info.Cost != current.Cost
+}
+
+// Verify reports whether data matches a bcrypt hash. bcrypt compares hashes in constant time.
+func (bcryptHasher) Verify(data string, info Info) bool {
+	if info.Encoded == "" {
+		return false
+	}
+
+	return bcrypt.CompareHashAndPassword([]byte(info.Encoded), []byte(data)) == nil
+}
@@ -0,0 +1,216 @@
+/* SPDX-License-Identifier: BSD-2-Clause
+
+Copyright (c) 2020-2026, Jef Oliver
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without modification,
+are permitted provided that the following conditions are met:
+
+  1. Redistributions of source code must retain the above copyright notice, this
+     list of conditions and the following disclaimer.
+
+  2. Redistributions in binary form must reproduce the above copyright notice
+     this list of conditions and the following disclaimer in the documentation
+     and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package hash
+
+import (
+	"strings"
+	"testing"
+)
+
+// test vectors from the OpenBSD bcrypt test suite
+const (
+	testBcryptHash  = "$2a$05$CCCCCCCCCCCCCCCCCCCCC.E5YPO9kmyuRGyh0XouQYb4YMJKvyOeW"
+	testBcryptHash2 = "$2a$05$CCCCCCCCCCCCCCCCCCCCC.VGOzA784oUp/Z0DY336zx7pLYAy0lwK"
+)
+
+func Test_bcryptHasherDecode(t *testing.T) {
+	tests := []struct {
+		name     string
+		hash     string
+		wantErr  bool
+		wantCost int
+	}{
+		{"2a", testBcryptHash, false, 5},
+		{"2b", strings.Replace(testBcryptHash, "$2a$", "$2b$", 1), false, 5},
+		{"2y", strings.Replace(testBcryptHash, "$2a$", "$2y$", 1), false, 5},
+		{"unknown variant", strings.Replace(testBcryptHash, "$2a$", "$2c$", 1), true, 0},
+		{"bad cost", strings.Replace(testBcryptHash, "$05$", "$99$", 1), true, 0},
+		{"short", testBcryptHash[:40], true, 0},
+		{"too many sections", testBcryptHash + "$", true, 0},
+		{"bad salt", strings.Replace(testBcryptHash, "CCCCCCCCCC", "CCCCCCCCC!", 1), true, 0},
+		{"bad hash", testBcryptHash[:len(testBcryptHash)-1] + "!", true, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Decode(tt.hash)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Decode() err = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			if got.Function != Bcrypt || got.Cost != tt.wantCost || got.SaltSize != 16 || got.KeySize != 23 ||
+				got.Encoded != tt.hash {
+				t.Errorf("Decode() got = %v", got)
+			}
+		})
+	}
+}
+
+func Test_bcryptHasherVerify(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		hash string
+		want bool
+	}{
+		{"2a", "U*U", testBcryptHash, true},
+		{"2a second vector", "U*U*", testBcryptHash2, true},
+		{"2b", "U*U", strings.Replace(testBcryptHash, "$2a$", "$2b$", 1), true},
+		{"2y", "U*U", strings.Replace(testBcryptHash, "$2a$", "$2y$", 1), true},
+		{"doesn't match", "U*U*", testBcryptHash, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Verify(tt.data, tt.hash)
+			if err != nil {
+				t.Fatalf("Verify() err = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Verify() got = %v, want = %v", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("no encoded form", func(t *testing.T) {
+		info, err := Decode(testBcryptHash)
+		if err != nil {
+			t.Fatal(err)
+		}
+		info.Encoded = ""
+
+		if MatchesAfterHash("U*U", info) {
+			t.Error("MatchesAfterHash() got = true, want false")
+		}
+	})
+}
+
+func Test_bcryptHasherGenerate(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		cost    int
+		wantErr bool
+	}{
+		{"empty data", "", 4, true},
+		{"too long", strings.Repeat("a", 73), 4, true},
+		{"cost too low", "testing data", 3, true},
+		{"cost too high", "testing data", 32, true},
+		{"good", "testing data", 4, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Generate(tt.data, Config{Cost: tt.cost, Function: Bcrypt})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Generate() err = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			if got.Cost != tt.cost || !strings.HasPrefix(got.Encoded, "$2a$04$") {
+				t.Errorf("Generate() got = %v", got)
+			}
+			if !MatchesAfterHash(tt.data, got) {
+				t.Error("MatchesAfterHash() got = false, want true")
+			}
+		})
+	}
+
+	t.Run("variant function", func(t *testing.T) {
+		for _, function := range []string{"2a", "2b", "2y"} {
+			if _, err := Generate("testing data", Config{Cost: 4, Function: function}); err == nil {
+				t.Errorf("Generate() with the %s function err = nil, want error", function)
+			}
+		}
+	})
+
+	t.Run("round trip", func(t *testing.T) {
+		config := Config{Cost: 4, Function: Bcrypt}
+
+		got, err := Generate("testing data", config)
+		if err != nil {
+			t.Fatalf("Generate() err = %v", err)
+		}
+
+		decoded, err := Decode(got.Encoded)
+		if err != nil {
+			t.Fatalf("Decode() err = %v", err)
+		}
+		if NeedsRehash(decoded, config) {
+			t.Error("NeedsRehash() got = true, want false")
+		}
+
+		matched, upgraded, err := VerifyAndUpgrade("testing data", got.Encoded, config)
+		if err != nil || !matched || upgraded != "" {
+			t.Errorf("VerifyAndUpgrade() got = %v, %q, %v, want true, \"\", nil", matched, upgraded, err)
+		}
+	})
+}
+
+func Test_bcryptHasherNeedsRehash(t *testing.T) {
+	info, err := Decode(testBcryptHash)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		current Config
+		want    bool
+	}{
+		{"same cost", Config{Cost: 5, Function: Bcrypt}, false},
+		{"higher cost", Config{Cost: 10, Function: Bcrypt}, true},
+		{"argon2id", testRehashConfig(), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NeedsRehash(info, tt.current); got != tt.want {
+				t.Errorf("NeedsRehash() got = %v, want = %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_bcryptUpgradeToArgon2(t *testing.T) {
+	got, upgraded, err := VerifyAndUpgrade("U*U", testBcryptHash, testRehashConfig())
+	if err != nil {
+		t.Fatalf("VerifyAndUpgrade() err = %v", err)
+	}
+	if !got {
+		t.Fatal("VerifyAndUpgrade() got = false, want true")
+	}
+	if !strings.HasPrefix(upgraded, "$argon2id$") {
+		t.Fatalf("VerifyAndUpgrade() upgraded = %q, want argon2id hash", upgraded)
+	}
+
+	if matches, err := Verify("U*U", upgraded); err != nil || !matches {
+		t.Errorf("Verify() got = %v, %v, want true", matches, err)
+	}
+}
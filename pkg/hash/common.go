@@ -40,6 +40,20 @@ const (
 	Argon2I = "argon2i"
 	// Argon2ID is the argon2id function constant
 	Argon2ID = "argon2id"
+	// Bcrypt is the bcrypt function constant
+	Bcrypt = "bcrypt"
+	// DjangoPBKDF2SHA1 is the function constant for PBKDF2-HMAC-SHA1 hashes in the Django format
+	DjangoPBKDF2SHA1 = "pbkdf2_sha1"
+	// DjangoPBKDF2SHA256 is the function constant for PBKDF2-HMAC-SHA256 hashes in the Django format
+	DjangoPBKDF2SHA256 = "pbkdf2_sha256"
+	// PBKDF2SHA1 is the function constant for PBKDF2-HMAC-SHA1 hashes in the passlib format
+	PBKDF2SHA1 = "pbkdf2"
+	// PBKDF2SHA256 is the function constant for PBKDF2-HMAC-SHA256 hashes in the passlib format
+	PBKDF2SHA256 = "pbkdf2-sha256"
+	// PBKDF2SHA512 is the function constant for PBKDF2-HMAC-SHA512 hashes in the passlib format
+	PBKDF2SHA512 = "pbkdf2-sha512"
+	// Scrypt is the scrypt function constant
+	Scrypt = "scrypt"
 )
 
 var (
@@ -53,12 +67,16 @@ var (
 
 // clearInfo clears an Info struct
 func clearInfo(info *Info) {
+	info.Cost = 0
 	info.Encoded = ""
 	info.Function = ""
 	info.Hash = nil
 	info.Iterations = 0
 	info.KeySize = 0
 	info.Memory = 0
+	info.N = 0
+	info.P = 0
+	info.R = 0
 	info.Salt = nil
 	info.SaltSize = 0
 	info.Threads = 0
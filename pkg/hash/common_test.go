@@ -40,10 +40,14 @@ func Test_clearInfo(t *testing.T) {
 
 		info := Info{
 			Config: Config{
+				Cost:       7,
 				Function:   Argon2ID,
 				Iterations: 1,
 				KeySize:    2,
 				Memory:     3,
+				N:          8,
+				P:          9,
+				R:          10,
 				SaltSize:   4,
 				Threads:    5,
 				Version:    6,
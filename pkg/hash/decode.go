@@ -39,18 +39,34 @@ import (
 const (
 	// errInvalidConfig is returned when the configuration section of a hash is malformed or missing
 	errInvalidConfig = "invalid hash configuration"
+	// errInvalidFormat is returned when a hash does not have the expected number of sections
+	errInvalidFormat = "invalid hash format"
 	// errInvalidVersion is returned when the version section of a hash is malformed or missing
 	errInvalidVersion = "invalid hash version"
 )
 
-// Decode decodes the provided hash
+// Decode decodes the provided hash, using the Hasher registered for the identifier at the start of the hash.
 //
 // When an error is encountered, a newly instantiated blank Info struct is returned to try to prevent as much
 // data leakage as possible.
 func Decode(data string) (Info, error) {
+	hasher, err := getHasher(hashID(data))
+	if err != nil {
+		return Info{}, err
+	}
+
+	return hasher.Decode(data)
+}
+
+// decodeArgon2 decodes the provided argon2 hash
+func decodeArgon2(data string) (Info, error) {
 	var err error
 
 	parts := strings.Split(data, "$")
+	if len(parts) != 6 || parts[0] != "" {
+		return Info{}, errors.New(errInvalidFormat)
+	}
+
 	ret := Info{Config: GetConfigDefaults()}
 
 	if ret.Function, err = decodeHashFunction(parts[1]); err != nil {
@@ -40,6 +40,14 @@ func Test_Decode(t *testing.T) {
 		wantErr bool
 		want    Info
 	}{
+		{"empty", "", true, Info{}},
+		{"too few sections", "$argon2id$v=19$m=65535,t=20,p=4$FgfkCqnF7CDOm5OigAR9EA", true, Info{}},
+		{"too many sections",
+			"$argon2id$v=19$m=65535,t=20,p=4$FgfkCqnF7CDOm5OigAR9EA$/hL4WFDYAQdfe8+D055mx1qQ9YBY24Tzyvcidlqrq5Y$",
+			true, Info{}},
+		{"no leading separator",
+			"argon2id$v=19$m=65535,t=20,p=4$FgfkCqnF7CDOm5OigAR9EA$/hL4WFDYAQdfe8+D055mx1qQ9YBY24Tzyvcidlqrq5Y$",
+			true, Info{}},
 		{"unknown function",
 			"$argon2$v=19$m=65535,t=20,p=4$FgfkCqnF7CDOm5OigAR9EA$/hL4WFDYAQdfe8+D055mx1qQ9YBY24Tzyvcidlqrq5Y",
 			true, Info{}},
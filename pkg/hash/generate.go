@@ -32,10 +32,41 @@ import (
 	"encoding/base64"
 	"errors"
 	"fmt"
+	"math"
 
 	"golang.org/x/crypto/argon2"
 )
 
+// DeriveKey derives a key from the provided data and salt, using the function and parameters in the provided config.
+// The derived key is KeySize bytes long. The SaltSize of the provided config is ignored in favor of the size of the
+// provided salt.
+func DeriveKey(data string, salt []byte, config Config) ([]byte, error) {
+	if data == "" {
+		return nil, errors.New("empty data")
+	}
+
+	saltSize := len(salt)
+	if saltSize == 0 || saltSize > math.MaxUint32 {
+		return nil, errors.New("invalid salt")
+	}
+
+	config.SaltSize = uint32(saltSize)
+	if err := validateConfig(&config); err != nil {
+		return nil, err
+	}
+
+	info := Info{Config: config, Salt: salt}
+	generateHash(data, &info)
+
+	return info.Hash, nil
+}
+
+// ValidateKeyConfig returns an error if the provided config cannot be used to derive keys with DeriveKey. The KeySize
+// and SaltSize of the provided config are checked along with its function and parameters.
+func ValidateKeyConfig(config Config) error {
+	return validateConfig(&config)
+}
+
 // encodeHash encodes the hashed password and information into a string per output from
 // https://github.com/P-H-C/phc-winner-argon2#command-line-utility
 func encodeHash(info *Info) {
@@ -81,10 +112,24 @@ func genSalt(info *Info) error {
 }
 
 // Generate hashes the provided data and creates an encoded string for storage, returning all information used to
-// create the hash.
+// create the hash. The Hasher registered for the Function of the provided config is used.
 //
 // If an error is encountered, an uninitialized Info struct is returned to prevent leaking of data to the caller.
 func Generate(data string, defaults Config) (Info, error) {
+	if data == "" {
+		return Info{}, errors.New("empty data")
+	}
+
+	hasher, err := getHasher(defaults.Function)
+	if err != nil {
+		return Info{}, err
+	}
+
+	return hasher.Generate(data, defaults)
+}
+
+// generateArgon2 hashes the provided data with argon2 and creates an encoded string for storage
+func generateArgon2(data string, defaults Config) (Info, error) {
 	var err error
 
 	if data == "" {
@@ -198,3 +198,59 @@ func Test_Generate(t *testing.T) {
 		})
 	}
 }
+
+func Test_DeriveKey(t *testing.T) {
+	salt := []byte{225, 180, 85, 191, 249, 180, 1, 23, 35, 201, 71, 154, 188, 68, 129, 167}
+
+	tests := []struct {
+		name    string
+		data    string
+		salt    []byte
+		config  Config
+		want    []byte
+		wantErr bool
+	}{
+		{"empty data", "", salt, GetConfigDefaults(), nil, true},
+		{"empty salt", "testing data", nil, GetConfigDefaults(), nil, true},
+		{"bad salt size", "testing data", []byte("short"), GetConfigDefaults(), nil, true},
+		{"bad config", "testing data", salt, Config{}, nil, true},
+		{"good", "testing data", salt, GetConfigDefaults(),
+			[]byte{169, 55, 89, 211, 172, 96, 199, 237, 53, 201, 60, 116, 204, 226, 192, 137, 172, 116, 92,
+				139, 211, 162, 66, 47, 7, 47, 102, 57, 36, 11, 127, 68},
+			false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DeriveKey(tt.data, tt.salt, tt.config)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("DeriveKey() err = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("DeriveKey() got = %v, want = %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_ValidateKeyConfig(t *testing.T) {
+	badSalt := GetConfigDefaults()
+	badSalt.SaltSize = 17
+
+	tests := []struct {
+		name    string
+		config  Config
+		wantErr bool
+	}{
+		{"empty config", Config{}, true},
+		{"bad salt size", badSalt, true},
+		{"good", GetConfigDefaults(), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidateKeyConfig(tt.config); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateKeyConfig() err = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -27,11 +27,47 @@ OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
 
 package hash
 
-// MatchesAfterHash generates hash info for the provided data, and then compares to the provided match info.
+import (
+	"crypto/subtle"
+	"math"
+)
+
+// MatchesAfterHash generates hash info for the provided data, and then compares to the provided match info. The Hasher
+// registered for the Function of the match info is used.
+//
+// The hash generated for the provided data is compared to the Hash of the match info in constant time.
 func MatchesAfterHash(data string, matchInfo Info) bool {
+	hasher, err := getHasher(matchInfo.Function)
+	if err != nil {
+		return false
+	}
+
+	return hasher.Verify(data, matchInfo)
+}
+
+// matchesArgon2 hashes the provided data with the argon2 parameters of the match info and compares the result to the
+// Hash of the match info in constant time
+func matchesArgon2(data string, matchInfo Info) bool {
+	hashSize := len(matchInfo.Hash)
+	if hashSize == 0 || hashSize > math.MaxUint32 {
+		return false
+	}
+
 	newInfo := matchInfo
+	newInfo.Hash = nil
+	newInfo.KeySize = uint32(hashSize)
 	generateHash(data, &newInfo)
-	encodeHash(&newInfo)
 
-	return newInfo.Encoded == matchInfo.Encoded
+	return subtle.ConstantTimeCompare(newInfo.Hash, matchInfo.Hash) == 1
+}
+
+// Verify decodes the provided encoded hash and reports whether the provided data matches it. An error is returned if
+// the encoded hash cannot be decoded.
+func Verify(data, encoded string) (bool, error) {
+	info, err := Decode(encoded)
+	if err != nil {
+		return false, err
+	}
+
+	return MatchesAfterHash(data, info), nil
 }
@@ -64,3 +64,72 @@ func Test_MatchesAfterHash(t *testing.T) {
 		})
 	}
 }
+
+func Test_MatchesAfterHashMismatchedInfo(t *testing.T) {
+	matchInfo := Info{
+		Config: Config{
+			Function:   "argon2id",
+			Iterations: 20,
+			KeySize:    32,
+			Memory:     65535,
+			SaltSize:   16,
+			Threads:    4,
+			Version:    19,
+		},
+		Hash: []byte{169, 55, 89, 211, 172, 96, 199, 237, 53, 201, 60, 116, 204, 226, 192, 137, 172, 116, 92,
+			139, 211, 162, 66, 47, 7, 47, 102, 57, 36, 11, 127, 68},
+		Salt: []byte{225, 180, 85, 191, 249, 180, 1, 23, 35, 201, 71, 154, 188, 68, 129, 167},
+	}
+
+	unknownFunction := matchInfo
+	unknownFunction.Function = "unknown"
+
+	noHash := matchInfo
+	noHash.Hash = nil
+
+	tests := []struct {
+		name string
+		info Info
+		want bool
+	}{
+		{"no encoded form", matchInfo, true},
+		{"unknown function", unknownFunction, false},
+		{"no hash", noHash, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := MatchesAfterHash("testing data", tt.info); got != tt.want {
+				t.Errorf("MatchesAfterHash() got = %v, want = %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_Verify(t *testing.T) {
+	encoded := "$argon2id$v=19$m=65535,t=20,p=4$4bRVv/m0ARcjyUeavESBpw$qTdZ06xgx+01yTx0zOLAiax0XIvTokIvBy9mOSQLf0Q"
+
+	tests := []struct {
+		name    string
+		data    string
+		encoded string
+		want    bool
+		wantErr bool
+	}{
+		{"doesn't match", "test data", encoded, false, false},
+		{"good", "testing data", encoded, true, false},
+		{"malformed", "testing data", "$argon2id$v=19", false, true},
+		{"not a hash", "testing data", "testing data", false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Verify(tt.data, tt.encoded)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Verify() err = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("Verify() got = %v, want = %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,240 @@
+/* SPDX-License-Identifier: BSD-2-Clause
+
+Copyright (c) 2020-2026, Jef Oliver
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without modification,
+are permitted provided that the following conditions are met:
+
+  1. Redistributions of source code must retain the above copyright notice, this
+     list of conditions and the following disclaimer.
+
+  2. Redistributions in binary form must reproduce the above copyright notice
+     this list of conditions and the following disclaimer in the documentation
+     and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package hash
+
+import (
+	"crypto/pbkdf2"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	stdhash "hash"
+	"strings"
+)
+
+const (
+	// PBKDF2MaxIterations is the largest number of iterations accepted for PBKDF2 hashes. The number of iterations is
+	// read from the hash itself, so it is limited to keep a single hash from tying up the process verifying it.
+	PBKDF2MaxIterations = 5_000_000
+
+	// djangoSaltChars are the characters Django uses in generated salts
+	djangoSaltChars = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+)
+
+// ab64Encoding is the base64 variant used by passlib, which replaces "+" with "." and omits padding
+var ab64Encoding = base64.NewEncoding("ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789./").
+	WithPadding(base64.NoPadding)
+
+// pbkdf2Hasher is the Hasher for PBKDF2 hashes.
+//
+// Django hashes are encoded as <function>$<iterations>$<salt>$<hash>, where the salt is stored as text and the hash is
+// base64 encoded. passlib hashes are encoded as $<function>$<iterations>$<salt>$<hash>, where the salt and hash are
+// encoded with passlib's adapted base64.
+type pbkdf2Hasher struct {
+	django   bool
+	function string
+	newHash  func() stdhash.Hash
+}
+
+// Decode decodes an encoded PBKDF2 hash
+func (p pbkdf2Hasher) Decode(data string) (Info, error) {
+	var err error
+
+	parts := strings.Split(data, "$")
+	if p.django {
+		// Django hashes do not start with a "$", so one is added to match the layout of passlib hashes.
+		parts = append([]string{""}, parts...)
+	}
+
+	if len(parts) != 5 || parts[0] != "" || parts[1] != p.function {
+		return Info{}, errors.New(errInvalidFormat)
+	}
+
+	ret := Info{Config: Config{Function: p.function}}
+
+	if ret.Iterations, err = decodeHashConfigUint32(parts[2], "invalid iterations configuration"); err != nil ||
+		ret.Iterations == 0 || ret.Iterations > PBKDF2MaxIterations {
+		return Info{}, errors.New("invalid iterations configuration")
+	}
+
+	if p.django {
+		ret.Salt, ret.Hash, err = decodeDjangoPBKDF2(parts[3], parts[4])
+	} else {
+		ret.Salt, ret.Hash, err = decodePasslibPBKDF2(parts[3], parts[4])
+	}
+
+	if err != nil {
+		return Info{}, err
+	}
+
+	// Both sections were limited to the size of a string when decoded, so their sizes always fit.
+	/* #nosec */
+	ret.KeySize = uint32(len(ret.Hash))
+	/* #nosec */
+	ret.SaltSize = uint32(len(ret.Salt))
+	ret.Encoded = data
+
+	return ret, nil
+}
+
+// Generate hashes data with PBKDF2, using the Iterations, KeySize and SaltSize of the provided config. A KeySize of
+// zero uses the size of the digest. Django hashes must use the size of the digest, and their SaltSize is the number
+// of characters in the salt.
+func (p pbkdf2Hasher) Generate(data string, config Config) (Info, error) {
+	if data == "" {
+		return Info{}, errors.New("empty data")
+	}
+
+	digestSize := uint32(p.newHash().Size())
+	if config.KeySize == 0 {
+		config.KeySize = digestSize
+	}
+
+	if config.Iterations < 1 || config.SaltSize < 1 {
+		return Info{}, errors.New("config cannot contain zero values")
+	}
+
+	if config.Iterations > PBKDF2MaxIterations {
+		return Info{}, fmt.Errorf("iterations of %d exceed the maximum of %d", config.Iterations, PBKDF2MaxIterations)
+	}
+
+	if p.django && config.KeySize != digestSize {
+		return Info{}, fmt.Errorf("keysize of %d does not match the digest size of %d", config.KeySize, digestSize)
+	}
+
+	config.Function = p.function
+	ret := Info{Config: config}
+
+	var err error
+	if p.django {
+		err = genDjangoSalt(&ret)
+	} else {
+		err = genSalt(&ret)
+	}
+
+	if err != nil {
+		clearInfo(&ret)
+		return Info{}, err
+	}
+
+	if ret.Hash, err = pbkdf2.Key(p.newHash, data, ret.Salt, int(ret.Iterations), int(ret.KeySize)); err != nil {
+		clearInfo(&ret)
+		return Info{}, err
+	}
+
+	if p.django {
+		ret.Encoded = fmt.Sprintf("%s$%d$%s$%s", p.function, ret.Iterations, ret.Salt,
+			base64.StdEncoding.EncodeToString(ret.Hash))
+	} else {
+		ret.Encoded = fmt.Sprintf("$%s$%d$%s$%s", p.function, ret.Iterations, ab64Encoding.EncodeToString(ret.Salt),
+			ab64Encoding.EncodeToString(ret.Hash))
+	}
+
+	return ret, nil
+}
+
+// NeedsRehash reports whether a PBKDF2 hash was created with parameters that differ from the provided config
+func (p pbkdf2Hasher) NeedsRehash(info Info, current Config) bool {
+	keySize := current.KeySize
+	if keySize == 0 {
+		keySize = uint32(p.newHash().Size())
+	}
+
+	return info.Iterations != current.Iterations ||
+		info.KeySize != keySize ||
+		info.SaltSize != current.SaltSize
+}
+
+// Verify reports whether data matches a PBKDF2 hash, comparing the hashes in constant time
+func (p pbkdf2Hasher) Verify(data string, info Info) bool {
+	if len(info.Hash) == 0 || info.Iterations == 0 || info.Iterations > PBKDF2MaxIterations {
+		return false
+	}
+
+	hash, err := pbkdf2.Key(p.newHash, data, info.Salt, int(info.Iterations), len(info.Hash))
+	if err != nil {
+		return false
+	}
+
+	return subtle.ConstantTimeCompare(hash, info.Hash) == 1
+}
+
+// decodeDjangoPBKDF2 decodes the text salt and base64 encoded hash of a Django PBKDF2 hash
+func decodeDjangoPBKDF2(salt, hash string) ([]byte, []byte, error) {
+	if salt == "" {
+		return nil, nil, errors.New("invalid hash salt")
+	}
+
+	hashBytes, err := base64.StdEncoding.DecodeString(hash)
+	if err != nil || len(hashBytes) == 0 {
+		return nil, nil, errors.New("invalid hash body")
+	}
+
+	return []byte(salt), hashBytes, nil
+}
+
+// decodePasslibPBKDF2 decodes the adapted base64 encoded salt and hash of a passlib PBKDF2 hash
+func decodePasslibPBKDF2(salt, hash string) ([]byte, []byte, error) {
+	saltBytes, err := ab64Encoding.DecodeString(salt)
+	if err != nil || len(saltBytes) == 0 {
+		return nil, nil, errors.New("invalid hash salt")
+	}
+
+	hashBytes, err := ab64Encoding.DecodeString(hash)
+	if err != nil || len(hashBytes) == 0 {
+		return nil, nil, errors.New("invalid hash body")
+	}
+
+	return saltBytes, hashBytes, nil
+}
+
+// genDjangoSalt generates a random salt of SaltSize alphanumeric characters, as Django does.
+func genDjangoSalt(info *Info) error {
+	// Random bytes at or above the largest multiple of the number of characters are discarded, so every character is
+	// equally likely.
+	limit := byte(256 - 256%len(djangoSaltChars))
+	salt := make([]byte, 0, info.SaltSize)
+	buf := make([]byte, info.SaltSize)
+
+	for uint32(len(salt)) < info.SaltSize {
+		if _, err := randReadFunc(buf); err != nil {
+			info.Salt = nil
+			return err
+		}
+
+		for _, b := range buf {
+			if b < limit && uint32(len(salt)) < info.SaltSize {
+				salt = append(salt, djangoSaltChars[int(b)%len(djangoSaltChars)])
+			}
+		}
+	}
+
+	info.Salt = salt
+
+	return nil
+}
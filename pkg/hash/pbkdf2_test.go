@@ -0,0 +1,230 @@
+/* SPDX-License-Identifier: BSD-2-Clause
+
+Copyright (c) 2020-2026, Jef Oliver
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without modification,
+are permitted provided that the following conditions are met:
+
+  1. Redistributions of source code must retain the above copyright notice, this
+     list of conditions and the following disclaimer.
+
+  2. Redistributions in binary form must reproduce the above copyright notice
+     this list of conditions and the following disclaimer in the documentation
+     and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package hash
+
+import (
+	"crypto/rand"
+	"errors"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func Test_pbkdf2HasherVerify(t *testing.T) {
+	// test vectors computed with hashlib.pbkdf2_hmac from Python, encoded in the Django and passlib formats
+	tests := []struct {
+		name    string
+		encoded string
+	}{
+		{DjangoPBKDF2SHA1, "pbkdf2_sha1$1000$saltsalt$6f6/9Uv85mj94wGsyFVjzJ3HHvY="},
+		{DjangoPBKDF2SHA256, "pbkdf2_sha256$1000$saltsaltsalt$sYIePhT5IXESDKvnouJXtE5pTJ6Znbmef4vViYmc9Uc="},
+		{PBKDF2SHA1, "$pbkdf2$1000$AAECAwQFBgcICQoLDA0ODw$Awni/k4L3.fQ/kgo1BwjRBbi2b8"},
+		{PBKDF2SHA256, "$pbkdf2-sha256$1000$AAECAwQFBgcICQoLDA0ODw$JeuGrMduQwGPGLmo.Qwv7UYtHHmeg9SK49fGkEamC2c"},
+		{PBKDF2SHA512, "$pbkdf2-sha512$1000$AAECAwQFBgcICQoLDA0ODw$x05AgND7tB/uWGjA/2D9dayuJjghWYfl/1T46uIRM5ta0a9uOH" +
+			"vBLdOnC7blqQEIFBxfCONToumEQ5pDM8Qtbg"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, err := Verify("password", tt.encoded); err != nil || !got {
+				t.Errorf("Verify() got = %v, %v, want true", got, err)
+			}
+			if got, err := Verify("Password", tt.encoded); err != nil || got {
+				t.Errorf("Verify() got = %v, %v, want false", got, err)
+			}
+		})
+	}
+
+	t.Run("bad parameters", func(t *testing.T) {
+		if MatchesAfterHash("password", Info{Config: Config{Function: PBKDF2SHA256}, Hash: []byte("hash")}) {
+			t.Error("MatchesAfterHash() without iterations got = true, want false")
+		}
+		if MatchesAfterHash("password", Info{Config: Config{Function: PBKDF2SHA256, Iterations: math.MaxUint32},
+			Hash: []byte("hash")}) {
+			t.Error("MatchesAfterHash() with too many iterations got = true, want false")
+		}
+		if MatchesAfterHash("password", Info{Config: Config{Function: PBKDF2SHA256, Iterations: 1}}) {
+			t.Error("MatchesAfterHash() without a hash got = true, want false")
+		}
+	})
+}
+
+func Test_pbkdf2HasherDecode(t *testing.T) {
+	django := "pbkdf2_sha1$1000$saltsalt$6f6/9Uv85mj94wGsyFVjzJ3HHvY="
+	passlib := "$pbkdf2$1000$AAECAwQFBgcICQoLDA0ODw$Awni/k4L3.fQ/kgo1BwjRBbi2b8"
+	hash := []byte{233, 254, 191, 245, 75, 252, 230, 104, 253, 227, 1, 172, 200, 85, 99, 204, 157, 199, 30, 246}
+
+	tests := []struct {
+		name    string
+		hash    string
+		wantErr bool
+		want    Info
+	}{
+		{"django too few sections", "pbkdf2_sha1$1000$saltsalt", true, Info{}},
+		{"django leading separator", "$" + django, true, Info{}},
+		{"django zero iterations", strings.Replace(django, "$1000$", "$0$", 1), true, Info{}},
+		{"django bad iterations", strings.Replace(django, "$1000$", "$a$", 1), true, Info{}},
+		{"django too many iterations", strings.Replace(django, "$1000$", "$4294967295$", 1), true, Info{}},
+		{"passlib too many iterations", strings.Replace(passlib, "$1000$", "$5000001$", 1), true, Info{}},
+		{"django no salt", strings.Replace(django, "saltsalt", "", 1), true, Info{}},
+		{"django bad hash", strings.TrimSuffix(django, "="), true, Info{}},
+		{"django no hash", strings.TrimSuffix(django, "6f6/9Uv85mj94wGsyFVjzJ3HHvY="), true, Info{}},
+		{"passlib too many sections", passlib + "$", true, Info{}},
+		{"passlib no leading separator", strings.TrimPrefix(passlib, "$"), true, Info{}},
+		{"passlib bad iterations", strings.Replace(passlib, "$1000$", "$-1$", 1), true, Info{}},
+		{"passlib bad salt", strings.Replace(passlib, "AAECAwQFBgcICQoLDA0ODw", "A", 1), true, Info{}},
+		{"passlib padded hash", passlib + "=", true, Info{}},
+		{"passlib no hash", strings.TrimSuffix(passlib, "Awni/k4L3.fQ/kgo1BwjRBbi2b8"), true, Info{}},
+		{"django good", django, false, Info{
+			Config:  Config{Function: DjangoPBKDF2SHA1, Iterations: 1000, KeySize: 20, SaltSize: 8},
+			Encoded: django,
+			Hash:    hash,
+			Salt:    []byte("saltsalt"),
+		}},
+		{"passlib good", passlib, false, Info{
+			Config:  Config{Function: PBKDF2SHA1, Iterations: 1000, KeySize: 20, SaltSize: 16},
+			Encoded: passlib,
+			Hash: []byte{3, 9, 226, 254, 78, 11, 223, 231, 208, 254, 72, 40, 212, 28, 35, 68, 22, 226, 217,
+				191},
+			Salt: []byte{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Decode(tt.hash)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Decode() err = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Decode() got = %v,\nwant = %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// nolint: gocognit
+func Test_pbkdf2HasherGenerate(t *testing.T) {
+	tests := []struct {
+		name       string
+		data       string
+		config     Config
+		readErr    bool
+		wantErr    bool
+		wantPrefix string
+	}{
+		{"empty data", "", Config{Function: PBKDF2SHA256, Iterations: 1000, SaltSize: 16}, false, true, ""},
+		{"no iterations", "testing data", Config{Function: PBKDF2SHA256, SaltSize: 16}, false, true, ""},
+		{"no salt size", "testing data", Config{Function: PBKDF2SHA256, Iterations: 1000}, false, true, ""},
+		{"too many iterations", "testing data",
+			Config{Function: PBKDF2SHA256, Iterations: PBKDF2MaxIterations + 1, SaltSize: 16}, false, true, ""},
+		{"django key size", "testing data",
+			Config{Function: DjangoPBKDF2SHA256, Iterations: 1000, KeySize: 16, SaltSize: 22}, false, true, ""},
+		{"bad readFunc", "testing data", Config{Function: PBKDF2SHA256, Iterations: 1000, SaltSize: 16}, true, true,
+			""},
+		{"django bad readFunc", "testing data", Config{Function: DjangoPBKDF2SHA1, Iterations: 1000, SaltSize: 22},
+			true, true, ""},
+		{"django sha1", "testing data", Config{Function: DjangoPBKDF2SHA1, Iterations: 1000, SaltSize: 22}, false,
+			false, "pbkdf2_sha1$1000$"},
+		{"django sha256", "testing data", Config{Function: DjangoPBKDF2SHA256, Iterations: 1000, SaltSize: 22},
+			false, false, "pbkdf2_sha256$1000$"},
+		{"passlib sha1", "testing data", Config{Function: PBKDF2SHA1, Iterations: 1000, SaltSize: 16}, false, false,
+			"$pbkdf2$1000$"},
+		{"passlib sha256", "testing data", Config{Function: PBKDF2SHA256, Iterations: 1000, SaltSize: 16}, false,
+			false, "$pbkdf2-sha256$1000$"},
+		{"passlib sha512 key size", "testing data",
+			Config{Function: PBKDF2SHA512, Iterations: 1000, KeySize: 32, SaltSize: 16}, false, false,
+			"$pbkdf2-sha512$1000$"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				randReadFunc = rand.Read
+			}()
+			if tt.readErr {
+				randReadFunc = func(_ []byte) (int, error) {
+					return 0, errors.New("testing error")
+				}
+			}
+
+			got, err := Generate(tt.data, tt.config)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Generate() err = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+
+			if !strings.HasPrefix(got.Encoded, tt.wantPrefix) {
+				t.Errorf("Generate() got.Encoded = %s, want prefix %s", got.Encoded, tt.wantPrefix)
+			}
+
+			decoded, err := Decode(got.Encoded)
+			if err != nil {
+				t.Fatalf("Decode() err = %v", err)
+			}
+			if !reflect.DeepEqual(decoded, got) {
+				t.Errorf("Decode() got = %v,\nwant = %v", decoded, got)
+			}
+			if !MatchesAfterHash(tt.data, decoded) {
+				t.Error("MatchesAfterHash() got = false, want true")
+			}
+			if NeedsRehash(decoded, tt.config) {
+				t.Error("NeedsRehash() got = true, want false")
+			}
+
+			tt.config.Iterations++
+			if !NeedsRehash(decoded, tt.config) {
+				t.Error("NeedsRehash() with more iterations got = false, want true")
+			}
+		})
+	}
+}
+
+func Test_genDjangoSalt(t *testing.T) {
+	defer func() {
+		randReadFunc = rand.Read
+	}()
+
+	// Bytes at or above 248 must be discarded, and the rest mapped onto the salt characters.
+	reads := [][]byte{{0, 255, 61, 62}, {248, 51, 1, 2}}
+	randReadFunc = func(b []byte) (int, error) {
+		n := copy(b, reads[0])
+		reads = reads[1:]
+		return n, nil
+	}
+
+	info := Info{Config: Config{SaltSize: 4}}
+	if err := genDjangoSalt(&info); err != nil {
+		t.Fatalf("genDjangoSalt() err = %v", err)
+	}
+	if string(info.Salt) != "a9aZ" {
+		t.Errorf("genDjangoSalt() got = %s, want = a9aZ", info.Salt)
+	}
+}
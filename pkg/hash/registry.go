@@ -0,0 +1,103 @@
+/* SPDX-License-Identifier: BSD-2-Clause
+
+Copyright (c) 2020-2026, Jef Oliver
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without modification,
+are permitted provided that the following conditions are met:
+
+  1. Redistributions of source code must retain the above copyright notice, this
+     list of conditions and the following disclaimer.
+
+  2. Redistributions in binary form must reproduce the above copyright notice
+     this list of conditions and the following disclaimer in the documentation
+     and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package hash
+
+import (
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+var (
+	// registry holds the Hashers available to Decode, Generate, MatchesAfterHash and NeedsRehash, keyed by identifier
+	registry = map[string]Hasher{
+		Argon2I:            argon2Hasher{},
+		Argon2ID:           argon2Hasher{},
+		Bcrypt:             bcryptHasher{},
+		DjangoPBKDF2SHA1:   pbkdf2Hasher{django: true, function: DjangoPBKDF2SHA1, newHash: sha1.New},
+		DjangoPBKDF2SHA256: pbkdf2Hasher{django: true, function: DjangoPBKDF2SHA256, newHash: sha256.New},
+		PBKDF2SHA1:         pbkdf2Hasher{function: PBKDF2SHA1, newHash: sha1.New},
+		PBKDF2SHA256:       pbkdf2Hasher{function: PBKDF2SHA256, newHash: sha256.New},
+		PBKDF2SHA512:       pbkdf2Hasher{function: PBKDF2SHA512, newHash: sha512.New},
+		Scrypt:             scryptHasher{},
+		// the bcrypt variants are registered to decode existing hashes, and cannot be used to generate them
+		"2a": bcryptHasher{},
+		"2b": bcryptHasher{},
+		"2y": bcryptHasher{},
+	}
+	// registryLock protects registry
+	registryLock sync.RWMutex
+)
+
+// Register registers a Hasher with the provided identifier, which is the Function of configs used with it and the
+// identifier at the start of hashes it creates. PHC formatted hashes start with "$id$", while other formats start with
+// "id$". An error is returned if a Hasher is already registered with the identifier.
+func Register(id string, hasher Hasher) error {
+	if id == "" || strings.Contains(id, "$") {
+		return fmt.Errorf("invalid hasher identifier: %q", id)
+	}
+
+	if hasher == nil {
+		return errors.New("no hasher provided")
+	}
+
+	registryLock.Lock()
+	defer registryLock.Unlock()
+
+	if _, ok := registry[id]; ok {
+		return fmt.Errorf("hasher already registered: %s", id)
+	}
+
+	registry[id] = hasher
+
+	return nil
+}
+
+// getHasher returns the Hasher registered with the provided identifier
+func getHasher(id string) (Hasher, error) {
+	registryLock.RLock()
+	defer registryLock.RUnlock()
+
+	hasher, ok := registry[id]
+	if !ok {
+		return nil, fmt.Errorf("unknown encode function: %s", id)
+	}
+
+	return hasher, nil
+}
+
+// hashID returns the identifier at the start of an encoded hash
+func hashID(data string) string {
+	id, _, _ := strings.Cut(strings.TrimPrefix(data, "$"), "$")
+
+	return id
+}
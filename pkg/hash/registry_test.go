@@ -0,0 +1,153 @@
+/* SPDX-License-Identifier: BSD-2-Clause
+
+Copyright (c) 2020-2026, Jef Oliver
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without modification,
+are permitted provided that the following conditions are met:
+
+  1. Redistributions of source code must retain the above copyright notice, this
+     list of conditions and the following disclaimer.
+
+  2. Redistributions in binary form must reproduce the above copyright notice
+     this list of conditions and the following disclaimer in the documentation
+     and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package hash
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// testHasher is a Hasher that stores data reversed, for testing the registry
+type testHasher struct{}
+
+func (testHasher) Decode(data string) (Info, error) {
+	hash, ok := strings.CutPrefix(data, "$test-reverse$")
+	if !ok || hash == "" {
+		return Info{}, errors.New("invalid test hash")
+	}
+
+	return Info{Config: Config{Function: "test-reverse", Version: 1}, Encoded: data, Hash: []byte(hash)}, nil
+}
+
+func (testHasher) Generate(data string, config Config) (Info, error) {
+	runes := []rune(data)
+	for i, j := 0, len(runes)-1; i < j; i, j = i+1, j-1 {
+		runes[i], runes[j] = runes[j], runes[i]
+	}
+
+	return Info{Config: config, Encoded: "$test-reverse$" + string(runes), Hash: []byte(string(runes))}, nil
+}
+
+func (testHasher) NeedsRehash(info Info, current Config) bool {
+	return info.Version != current.Version
+}
+
+func (t testHasher) Verify(data string, info Info) bool {
+	generated, _ := t.Generate(data, info.Config)
+
+	return string(generated.Hash) == string(info.Hash)
+}
+
+// nolint: gocognit
+func Test_Register(t *testing.T) {
+	if err := Register("test-reverse", testHasher{}); err != nil {
+		t.Fatalf("Register() err = %v", err)
+	}
+
+	t.Run("dispatch", func(t *testing.T) {
+		info, err := Generate("testing data", Config{Function: "test-reverse", Version: 1})
+		if err != nil {
+			t.Fatalf("Generate() err = %v", err)
+		}
+		if info.Encoded != "$test-reverse$atad gnitset" {
+			t.Errorf("Generate() got.Encoded = %s, want $test-reverse$atad gnitset", info.Encoded)
+		}
+
+		decoded, err := Decode(info.Encoded)
+		if err != nil {
+			t.Fatalf("Decode() err = %v", err)
+		}
+		if !MatchesAfterHash("testing data", decoded) || MatchesAfterHash("test data", decoded) {
+			t.Error("MatchesAfterHash() did not dispatch to the registered hasher")
+		}
+		if NeedsRehash(decoded, Config{Function: "test-reverse", Version: 1}) {
+			t.Error("NeedsRehash() got = true, want false")
+		}
+		if !NeedsRehash(decoded, Config{Function: "test-reverse", Version: 2}) {
+			t.Error("NeedsRehash() got = false, want true")
+		}
+		if !NeedsRehash(decoded, GetConfigDefaults()) {
+			t.Error("NeedsRehash() with a different function got = false, want true")
+		}
+	})
+
+	tests := []struct {
+		name   string
+		id     string
+		hasher Hasher
+	}{
+		{"empty identifier", "", testHasher{}},
+		{"identifier with separator", "test$reverse", testHasher{}},
+		{"no hasher", "test-nil", nil},
+		{"already registered", "test-reverse", testHasher{}},
+		{"built in", Argon2ID, testHasher{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := Register(tt.id, tt.hasher); err == nil {
+				t.Error("Register() err = nil, want error")
+			}
+		})
+	}
+}
+
+func Test_hashID(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want string
+	}{
+		{"empty", "", ""},
+		{"phc", "$argon2id$v=19$m=65535,t=20,p=4$salt$hash", Argon2ID},
+		{"no leading separator", "pbkdf2_sha256$260000$salt$hash", "pbkdf2_sha256"},
+		{"no separator", "testing data", "testing data"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := hashID(tt.data); got != tt.want {
+				t.Errorf("hashID() got = %v, want = %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_UnknownHasher(t *testing.T) {
+	if _, err := Decode("$unknown$hash"); err == nil {
+		t.Error("Decode() err = nil, want error")
+	}
+	if _, err := Generate("testing data", Config{Function: "unknown"}); err == nil {
+		t.Error("Generate() err = nil, want error")
+	}
+	if MatchesAfterHash("testing data", Info{Config: Config{Function: "unknown"}, Hash: []byte("hash")}) {
+		t.Error("MatchesAfterHash() got = true, want false")
+	}
+	if !NeedsRehash(Info{Config: Config{Function: "unknown"}}, Config{Function: "unknown"}) {
+		t.Error("NeedsRehash() got = false, want true")
+	}
+}
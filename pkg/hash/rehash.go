@@ -0,0 +1,73 @@
+/* SPDX-License-Identifier: BSD-2-Clause
+
+Copyright (c) 2020-2026, Jef Oliver
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without modification,
+are permitted provided that the following conditions are met:
+
+  1. Redistributions of source code must retain the above copyright notice, this
+     list of conditions and the following disclaimer.
+
+  2. Redistributions in binary form must reproduce the above copyright notice
+     this list of conditions and the following disclaimer in the documentation
+     and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package hash
+
+// NeedsRehash reports whether the provided hash info was created with parameters that differ from the provided
+// current config, meaning the data should be hashed again with the current config the next time it is available.
+//
+// Hashes created with a different function always need to be rehashed. Otherwise, the Hasher registered for the
+// function compares its parameters.
+func NeedsRehash(info Info, current Config) bool {
+	if info.Function != current.Function {
+		return true
+	}
+
+	hasher, err := getHasher(info.Function)
+	if err != nil {
+		return true
+	}
+
+	return hasher.NeedsRehash(info, current)
+}
+
+// VerifyAndUpgrade verifies the provided data against the provided encoded hash. If the data matches and the hash
+// needs to be rehashed with the current config, a newly generated encoded hash is returned so it can replace the
+// stored hash. Otherwise, the returned encoded hash is empty.
+//
+// An error is returned if the encoded hash cannot be decoded, or if a new hash cannot be generated.
+func VerifyAndUpgrade(data, encoded string, current Config) (bool, string, error) {
+	info, err := Decode(encoded)
+	if err != nil {
+		return false, "", err
+	}
+
+	if !MatchesAfterHash(data, info) {
+		return false, "", nil
+	}
+
+	if !NeedsRehash(info, current) {
+		return true, "", nil
+	}
+
+	upgraded, err := Generate(data, current)
+	if err != nil {
+		return true, "", err
+	}
+
+	return true, upgraded.Encoded, nil
+}
@@ -0,0 +1,139 @@
+/* SPDX-License-Identifier: BSD-2-Clause
+
+Copyright (c) 2020-2026, Jef Oliver
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without modification,
+are permitted provided that the following conditions are met:
+
+  1. Redistributions of source code must retain the above copyright notice, this
+     list of conditions and the following disclaimer.
+
+  2. Redistributions in binary form must reproduce the above copyright notice
+     this list of conditions and the following disclaimer in the documentation
+     and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package hash
+
+import (
+	"testing"
+)
+
+// testRehashConfig returns an inexpensive config for testing
+func testRehashConfig() Config {
+	return Config{
+		Function:   Argon2ID,
+		Iterations: 1,
+		KeySize:    32,
+		Memory:     64,
+		SaltSize:   16,
+		Threads:    1,
+		Version:    0x13,
+	}
+}
+
+func Test_NeedsRehash(t *testing.T) {
+	info, err := Generate("testing data", testRehashConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	decoded, err := Decode(info.Encoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		change func(*Config)
+		want   bool
+	}{
+		{"same", func(_ *Config) {}, false},
+		{"function", func(c *Config) { c.Function = Argon2I }, true},
+		{"version", func(c *Config) { c.Version = 0x10 }, true},
+		{"memory", func(c *Config) { c.Memory = 128 }, true},
+		{"iterations", func(c *Config) { c.Iterations = 2 }, true},
+		{"threads", func(c *Config) { c.Threads = 2 }, true},
+		{"key size", func(c *Config) { c.KeySize = 64 }, true},
+		{"salt size", func(c *Config) { c.SaltSize = 32 }, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			current := testRehashConfig()
+			tt.change(&current)
+
+			if got := NeedsRehash(decoded, current); got != tt.want {
+				t.Errorf("NeedsRehash() got = %v, want = %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// nolint: gocognit
+func Test_VerifyAndUpgrade(t *testing.T) {
+	stale, err := Generate("testing data", testRehashConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	current := testRehashConfig()
+	current.Iterations = 2
+
+	badCurrent := current
+	badCurrent.KeySize = 0
+
+	tests := []struct {
+		name        string
+		data        string
+		encoded     string
+		current     Config
+		want        bool
+		wantUpgrade bool
+		wantErr     bool
+	}{
+		{"doesn't match", "test data", stale.Encoded, current, false, false, false},
+		{"up to date", "testing data", stale.Encoded, testRehashConfig(), true, false, false},
+		{"upgraded", "testing data", stale.Encoded, current, true, true, false},
+		{"malformed", "testing data", "$argon2id", current, false, false, true},
+		{"bad current config", "testing data", stale.Encoded, badCurrent, true, false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, upgraded, err := VerifyAndUpgrade(tt.data, tt.encoded, tt.current)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("VerifyAndUpgrade() err = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("VerifyAndUpgrade() got = %v, want = %v", got, tt.want)
+			}
+			if (upgraded != "") != tt.wantUpgrade {
+				t.Errorf("VerifyAndUpgrade() upgraded = %q, wantUpgrade %v", upgraded, tt.wantUpgrade)
+				return
+			}
+			if !tt.wantUpgrade {
+				return
+			}
+
+			info, err := Decode(upgraded)
+			if err != nil {
+				t.Fatalf("Decode() err = %v", err)
+			}
+			if NeedsRehash(info, tt.current) || !MatchesAfterHash(tt.data, info) {
+				t.Errorf("VerifyAndUpgrade() upgraded = %q, want hash of data with current config", upgraded)
+			}
+		})
+	}
+}
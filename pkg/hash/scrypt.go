@@ -0,0 +1,217 @@
+/* SPDX-License-Identifier: BSD-2-Clause
+
+Copyright (c) 2020-2026, Jef Oliver
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without modification,
+are permitted provided that the following conditions are met:
+
+  1. Redistributions of source code must retain the above copyright notice, this
+     list of conditions and the following disclaimer.
+
+  2. Redistributions in binary form must reproduce the above copyright notice
+     this list of conditions and the following disclaimer in the documentation
+     and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package hash
+
+import (
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math"
+	"math/bits"
+	"strings"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+// The cost of an scrypt hash is read from the hash itself, so it is limited to keep a single hash from exhausting the
+// memory or time of the process verifying it.
+const (
+	// ScryptMaxLogN is the largest base 2 logarithm of N accepted for scrypt hashes
+	ScryptMaxLogN = 24
+	// ScryptMaxMemory is the largest amount of memory, in bytes, accepted for scrypt hashes, calculated as 128·r·(N+p)
+	ScryptMaxMemory = 1 << 30
+	// ScryptMaxWork is the largest amount of mixing, in bytes, accepted for scrypt hashes, calculated as 128·N·r·p
+	ScryptMaxWork = 1 << 32
+)
+
+// scryptHasher is the Hasher for scrypt hashes, encoded in the PHC string format as
+// $scrypt$ln=<log2(N)>,r=<r>,p=<p>$<salt>$<hash>
+type scryptHasher struct{}
+
+// Decode decodes an encoded scrypt hash
+func (scryptHasher) Decode(data string) (Info, error) {
+	var err error
+
+	parts := strings.Split(data, "$")
+	if len(parts) != 5 || parts[0] != "" || parts[1] != Scrypt {
+		return Info{}, errors.New(errInvalidFormat)
+	}
+
+	ret := Info{Config: Config{Function: Scrypt}}
+
+	if err = decodeScryptConfig(parts[2], &ret); err != nil {
+		return Info{}, err
+	}
+
+	if ret.Salt, ret.SaltSize, err = decodeHashBytes(parts[3], "invalid hash salt"); err != nil {
+		return Info{}, err
+	}
+
+	if ret.Hash, ret.KeySize, err = decodeHashBytes(parts[4], "invalid hash body"); err != nil {
+		return Info{}, err
+	}
+
+	ret.Encoded = data
+
+	return ret, nil
+}
+
+// Generate hashes data with scrypt, using the N, R, P, KeySize and SaltSize of the provided config
+func (scryptHasher) Generate(data string, config Config) (Info, error) {
+	if data == "" {
+		return Info{}, errors.New("empty data")
+	}
+
+	if err := validateScryptConfig(&config); err != nil {
+		return Info{}, err
+	}
+
+	ret := Info{Config: config}
+	if err := genSalt(&ret); err != nil {
+		clearInfo(&ret)
+		return Info{}, err
+	}
+
+	hash, err := scrypt.Key([]byte(data), ret.Salt, ret.N, ret.R, ret.P, int(ret.KeySize))
+	if err != nil {
+		clearInfo(&ret)
+		return Info{}, err
+	}
+
+	ret.Hash = hash
+	ret.Encoded = fmt.Sprintf("$%s$ln=%d,r=%d,p=%d$%s$%s", Scrypt, bits.Len(uint(ret.N))-1, ret.R, ret.P,
+		base64.RawStdEncoding.EncodeToString(ret.Salt), base64.RawStdEncoding.EncodeToString(ret.Hash))
+
+	return ret, nil
+}
+
+// NeedsRehash reports whether an scrypt hash was created with parameters that differ from the provided config
+func (scryptHasher) NeedsRehash(info Info, current Config) bool {
+	return info.N != current.N ||
+		info.R != current.R ||
+		info.P != current.P ||
+		info.KeySize != current.KeySize ||
+		info.SaltSize != current.SaltSize
+}
+
+// Verify reports whether data matches an scrypt hash, comparing the hashes in constant time
+func (scryptHasher) Verify(data string, info Info) bool {
+	if len(info.Hash) == 0 {
+		return false
+	}
+
+	if validateScryptCost(info.N, info.R, info.P) != nil {
+		return false
+	}
+
+	hash, err := scrypt.Key([]byte(data), info.Salt, info.N, info.R, info.P, len(info.Hash))
+	if err != nil {
+		return false
+	}
+
+	return subtle.ConstantTimeCompare(hash, info.Hash) == 1
+}
+
+// decodeScryptConfig decodes the ln, r and p parameters of an encoded scrypt hash
+// nolint:cyclop,gocyclo,gocognit
+// decoding does take some cycles to run
+func decodeScryptConfig(configInfo string, info *Info) error {
+	parts := strings.Split(configInfo, ",")
+	if len(parts) != 3 {
+		return errors.New(errInvalidConfig)
+	}
+
+	for _, part := range parts {
+		key, value, ok := strings.Cut(part, "=")
+		if !ok {
+			return errors.New(errInvalidConfig)
+		}
+
+		parsed, err := decodeHashConfigUint32(value, errInvalidConfig)
+		if err != nil || parsed == 0 || parsed > math.MaxInt32 {
+			return errors.New(errInvalidConfig)
+		}
+
+		switch key {
+		case "ln":
+			if parsed > ScryptMaxLogN {
+				return errors.New(errInvalidConfig)
+			}
+			info.N = 1 << parsed
+		case "r":
+			info.R = int(parsed)
+		case "p":
+			info.P = int(parsed)
+		default:
+			return errors.New(errInvalidConfig)
+		}
+	}
+
+	if info.N == 0 || info.R == 0 || info.P == 0 {
+		return errors.New(errInvalidConfig)
+	}
+
+	return validateScryptCost(info.N, info.R, info.P)
+}
+
+// validateScryptConfig validates that the provided config can be used to generate an scrypt hash
+func validateScryptConfig(config *Config) error {
+	if config.N < 2 || config.N&(config.N-1) != 0 {
+		return fmt.Errorf("scrypt N must be a power of 2 greater than 1: %d", config.N)
+	}
+
+	if config.R < 1 || config.P < 1 || uint64(config.R)*uint64(config.P) >= 1<<30 {
+		return fmt.Errorf("invalid scrypt r and p: %d, %d", config.R, config.P)
+	}
+
+	if err := validateScryptCost(config.N, config.R, config.P); err != nil {
+		return err
+	}
+
+	if config.KeySize < 1 || config.SaltSize < 1 {
+		return errors.New("config cannot contain zero values")
+	}
+
+	return nil
+}
+
+// validateScryptCost validates that the provided scrypt parameters do not exceed ScryptMaxLogN, ScryptMaxMemory and
+// ScryptMaxWork
+func validateScryptCost(n, r, p int) error {
+	if n < 2 || n > 1<<ScryptMaxLogN || r < 1 || p < 1 || uint64(r)*uint64(p) >= 1<<30 {
+		return fmt.Errorf("invalid scrypt parameters: N %d, r %d, p %d", n, r, p)
+	}
+
+	// N is at most 2^24 and r·p less than 2^30, so dividing the limits keeps the checks from overflowing.
+	if uint64(r) > ScryptMaxMemory/128/(uint64(n)+uint64(p)) || uint64(r)*uint64(p) > ScryptMaxWork/128/uint64(n) {
+		return fmt.Errorf("scrypt parameters exceed the maximum cost: N %d, r %d, p %d", n, r, p)
+	}
+
+	return nil
+}
@@ -0,0 +1,228 @@
+/* SPDX-License-Identifier: BSD-2-Clause
+
+Copyright (c) 2020-2026, Jef Oliver
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without modification,
+are permitted provided that the following conditions are met:
+
+  1. Redistributions of source code must retain the above copyright notice, this
+     list of conditions and the following disclaimer.
+
+  2. Redistributions in binary form must reproduce the above copyright notice
+     this list of conditions and the following disclaimer in the documentation
+     and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package hash
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// nolint: gocognit
+func Test_scryptHasherVerify(t *testing.T) {
+	// test vectors from https://www.rfc-editor.org/rfc/rfc7914#section-12
+	tests := []struct {
+		name string
+		data string
+		salt string
+		n    int
+		r    int
+		p    int
+		want string
+	}{
+		{"empty", "", "", 16, 1, 1,
+			"77d6576238657b203b19ca42c18a0497f16b4844e3074ae8dfdffa3fede21442fcd0069ded0948f8326a753a0fc81f17e8d3e0fb2" +
+				"e0d3628cf35e20c38d18906"},
+		{"password", "password", "NaCl", 1024, 8, 16,
+			"fdbabe1c9d3472007856e7190d01e9fe7c6ad7cbc8237830e77376634b3731622eaf30d92e22a3886ff109279d9830dac727afb94" +
+				"a83ee6d8360cbdfa2cc0640"},
+		{"pleaseletmein", "pleaseletmein", "SodiumChloride", 16384, 8, 1,
+			"7023bdcb3afd7348461c06cd81fd38ebfda8fbba904f8e3ea9b543f6545da1f2d5432955613f0fcf62d49705242a9af9e61e85dc0" +
+				"d651e40dfcf017b45575887"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hash, err := hex.DecodeString(tt.want)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			info := Info{
+				Config: Config{Function: Scrypt, N: tt.n, P: tt.p, R: tt.r},
+				Hash:   hash,
+				Salt:   []byte(tt.salt),
+			}
+
+			if !MatchesAfterHash(tt.data, info) {
+				t.Error("MatchesAfterHash() got = false, want true")
+			}
+			if MatchesAfterHash(tt.data+"x", info) {
+				t.Error("MatchesAfterHash() with other data got = true, want false")
+			}
+		})
+	}
+
+	t.Run("encoded", func(t *testing.T) {
+		hash, err := hex.DecodeString("fdbabe1c9d3472007856e7190d01e9fe7c6ad7cbc8237830e77376634b3731622eaf30d92e2" +
+			"2a3886ff109279d9830dac727afb94a83ee6d8360cbdfa2cc0640")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		encoded := "$scrypt$ln=10,r=8,p=16$" + base64.RawStdEncoding.EncodeToString([]byte("NaCl")) + "$" +
+			base64.RawStdEncoding.EncodeToString(hash)
+
+		if got, err := Verify("password", encoded); err != nil || !got {
+			t.Errorf("Verify() got = %v, %v, want true", got, err)
+		}
+		if got, err := Verify("Password", encoded); err != nil || got {
+			t.Errorf("Verify() got = %v, %v, want false", got, err)
+		}
+	})
+
+	t.Run("bad parameters", func(t *testing.T) {
+		if MatchesAfterHash("password", Info{Config: Config{Function: Scrypt, N: 3, P: 1, R: 1}, Hash: []byte("hash")}) {
+			t.Error("MatchesAfterHash() got = true, want false")
+		}
+		if MatchesAfterHash("password", Info{Config: Config{Function: Scrypt, N: 1 << 40, P: 1, R: 1},
+			Hash: []byte("hash")}) {
+			t.Error("MatchesAfterHash() with an oversized N got = true, want false")
+		}
+		if MatchesAfterHash("password", Info{Config: Config{Function: Scrypt, N: 16, P: 1, R: 1}}) {
+			t.Error("MatchesAfterHash() without a hash got = true, want false")
+		}
+	})
+}
+
+func Test_scryptHasherDecode(t *testing.T) {
+	good := "$scrypt$ln=4,r=8,p=1$TmFDbA$/hL4WFDYAQdfe8+D055mx1qQ9YBY24Tzyvcidlqrq5Y"
+
+	tests := []struct {
+		name    string
+		hash    string
+		wantErr bool
+		want    Info
+	}{
+		{"too few sections", "$scrypt$ln=4,r=8,p=1$TmFDbA", true, Info{}},
+		{"argon2 style version", "$scrypt$v=1$ln=4,r=8,p=1$TmFDbA$/hL4WFDYAQdfe8+D055mx1qQ9YBY24Tzyvcidlqrq5Y",
+			true, Info{}},
+		{"too few parameters", strings.Replace(good, "ln=4,", "", 1), true, Info{}},
+		{"unknown parameter", strings.Replace(good, "ln=4", "n=16", 1), true, Info{}},
+		{"repeated parameter", strings.Replace(good, "p=1", "r=1", 1), true, Info{}},
+		{"no value", strings.Replace(good, "ln=4", "ln", 1), true, Info{}},
+		{"zero value", strings.Replace(good, "r=8", "r=0", 1), true, Info{}},
+		{"not a number", strings.Replace(good, "p=1", "p=a", 1), true, Info{}},
+		{"ln too large", strings.Replace(good, "ln=4", "ln=40", 1), true, Info{}},
+		{"memory too large", strings.Replace(good, "ln=4", "ln=24", 1), true, Info{}},
+		{"work too large", strings.Replace(strings.Replace(good, "ln=4", "ln=16", 1), "p=1", "p=128", 1), true,
+			Info{}},
+		{"bad salt", strings.Replace(good, "TmFDbA", "", 1), true, Info{}},
+		{"bad hash", strings.TrimSuffix(good, "/hL4WFDYAQdfe8+D055mx1qQ9YBY24Tzyvcidlqrq5Y"), true, Info{}},
+		{"good", good, false, Info{
+			Config:  Config{Function: Scrypt, KeySize: 32, N: 16, P: 1, R: 8, SaltSize: 4},
+			Encoded: good,
+			Hash: []byte{254, 18, 248, 88, 80, 216, 1, 7, 95, 123, 207, 131, 211, 158, 102, 199,
+				90, 144, 245, 128, 88, 219, 132, 243, 202, 247, 34, 118, 90, 171, 171, 150},
+			Salt: []byte("NaCl"),
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Decode(tt.hash)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Decode() err = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Decode() got = %v,\nwant = %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// nolint: gocognit
+func Test_scryptHasherGenerate(t *testing.T) {
+	good := Config{Function: Scrypt, KeySize: 32, N: 16, P: 1, R: 8, SaltSize: 16}
+
+	tests := []struct {
+		name    string
+		data    string
+		change  func(*Config)
+		readErr bool
+		wantErr bool
+	}{
+		{"empty data", "", func(_ *Config) {}, false, true},
+		{"n not a power of 2", "testing data", func(c *Config) { c.N = 15 }, false, true},
+		{"n too small", "testing data", func(c *Config) { c.N = 1 }, false, true},
+		{"no r", "testing data", func(c *Config) { c.R = 0 }, false, true},
+		{"r and p too large", "testing data", func(c *Config) { c.R, c.P = 1<<15, 1<<15 }, false, true},
+		{"n too large", "testing data", func(c *Config) { c.N = 1 << 25 }, false, true},
+		{"memory too large", "testing data", func(c *Config) { c.N = 1 << 21 }, false, true},
+		{"no key size", "testing data", func(c *Config) { c.KeySize = 0 }, false, true},
+		{"bad readFunc", "testing data", func(_ *Config) {}, true, true},
+		{"good", "testing data", func(_ *Config) {}, false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				randReadFunc = rand.Read
+			}()
+			if tt.readErr {
+				randReadFunc = func(_ []byte) (int, error) {
+					return 0, errors.New("testing error")
+				}
+			}
+
+			config := good
+			tt.change(&config)
+
+			got, err := Generate(tt.data, config)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Generate() err = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+
+			if !strings.HasPrefix(got.Encoded, "$scrypt$ln=4,r=8,p=1$") {
+				t.Errorf("Generate() got.Encoded = %s", got.Encoded)
+			}
+
+			decoded, err := Decode(got.Encoded)
+			if err != nil {
+				t.Fatalf("Decode() err = %v", err)
+			}
+			if !MatchesAfterHash(tt.data, decoded) {
+				t.Error("MatchesAfterHash() got = false, want true")
+			}
+			if NeedsRehash(decoded, config) {
+				t.Error("NeedsRehash() got = true, want false")
+			}
+
+			config.N = 32
+			if !NeedsRehash(decoded, config) {
+				t.Error("NeedsRehash() with a higher N got = false, want true")
+			}
+		})
+	}
+}
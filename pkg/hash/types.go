@@ -29,15 +29,29 @@ package hash
 
 // Config holds the default configuration values for the hash module.
 type Config struct {
+	Cost       int    `json:"cost,omitempty" toml:"cost"`             // Cost is the bcrypt cost, the base 2 logarithm of the number of rounds.
 	Function   string `json:"function,omitempty" toml:"function"`     // Function is the name of the function to create the hash.
 	Iterations uint32 `json:"iterations.omitempty" toml:"iterations"` // Iterations is the number of passes over hashing memory should occur.
 	KeySize    uint32 `json:"key_size,omitempty" toml:"key_size"`     // KeySize is the size, in bytes, the returned derived key should be. Must be a multiple of 32.
 	Memory     uint32 `json:"memory,omitempty" toml:"memory"`         // Memory is the size of memory, in kilobytes, to be used in iteration during hashing.
+	N          int    `json:"n,omitempty" toml:"n"`                   // N is the scrypt CPU/memory cost parameter. Must be a power of 2 greater than 1.
+	P          int    `json:"p,omitempty" toml:"p"`                   // P is the scrypt parallelization parameter.
+	R          int    `json:"r,omitempty" toml:"r"`                   // R is the scrypt block size parameter.
 	SaltSize   uint32 `json:"salt_size,omitempty" toml:"salt_size"`   // SaltSize is the size, in bytes, that randomly generated salt to be used for hashing should be. Must be a multiple of 16.
 	Threads    uint8  `json:"threads,omitempty" toml:"threads"`       // Threads is the number of threads to be used in the hashing process.
 	Version    int    `json:"version,omitempty" toml:"version"`       // Version is the default version fo the argon hashing algorithms to use for hashing.
 }
 
+// Hasher is an interface for a hashing algorithm that can be registered with this package, so Decode, Generate,
+// MatchesAfterHash and NeedsRehash can dispatch to it based on the Function of a Config, or the identifier at the start
+// of an encoded hash.
+type Hasher interface {
+	Decode(string) (Info, error)           // Decode decodes an encoded hash created by the algorithm
+	Generate(string, Config) (Info, error) // Generate hashes data with the provided config
+	NeedsRehash(Info, Config) bool         // NeedsRehash reports whether a hash was created with outdated parameters
+	Verify(string, Info) bool              // Verify reports whether data matches a hash, comparing in constant time
+}
+
 // Info holds information about a hash
 type Info struct {
 	Config // Config is an embedded config struct